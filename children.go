@@ -0,0 +1,82 @@
+package enigma
+
+import (
+	"context"
+	"strconv"
+)
+
+// ChildTableIterator walks the children tables of a parent node, page by page.
+//
+//    it := client.Meta().ChildrenTables(ctx, "us.gov.whitehouse")
+//    for it.Next() {
+//        fmt.Println(it.Table().Datapath)
+//    }
+//    if err := it.Err(); err != nil {
+//        fmt.Println(err)
+//    }
+type ChildTableIterator struct {
+	query    *MetaQuery
+	ctx      context.Context
+	datapath string
+	page     int // Next page to fetch.
+	total    int // Total number of pages, -1 until the first page is fetched.
+	parent   *MetaParentNodeResponse
+	tables   []ChildTable
+	table    ChildTable
+	err      error
+}
+
+// ChildrenTables returns a ChildTableIterator over the children tables of the parent node
+// at datapath, across all pages, starting from the page set with Page(), if any.
+func (q *MetaQuery) ChildrenTables(ctx context.Context, datapath string) *ChildTableIterator {
+	page, err := strconv.Atoi(q.params.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	return &ChildTableIterator{query: q, ctx: ctx, datapath: datapath, page: page, total: -1}
+}
+
+// Next advances the iterator to the next table, fetching the next page if needed.
+// It returns false when all the tables have been read, or an error occurred.
+func (it *ChildTableIterator) Next() bool {
+	for len(it.tables) == 0 {
+		if it.err != nil || (it.total >= 0 && it.page > it.total) {
+			it.table = ChildTable{}
+			return false
+		}
+		it.advance()
+	}
+	it.table, it.tables = it.tables[0], it.tables[1:]
+	return true
+}
+
+// Table returns the current table.
+func (it *ChildTableIterator) Table() ChildTable {
+	return it.table
+}
+
+// Parent returns the first page fetched for the parent node, which describes its path
+// and immediate nodes. It is nil until Next is called.
+func (it *ChildTableIterator) Parent() *MetaParentNodeResponse {
+	return it.parent
+}
+
+// Err returns the error, if any, that interrupted the iteration.
+func (it *ChildTableIterator) Err() error {
+	return it.err
+}
+
+// advance loads the tables of the next page.
+func (it *ChildTableIterator) advance() {
+	response, err := it.query.Page(it.page).ParentContext(it.ctx, it.datapath)
+	if err != nil {
+		it.err = err
+		return
+	}
+	if it.parent == nil {
+		it.parent = response
+	}
+	it.tables = response.Result.ChildrenTables
+	it.total = response.Info.TotalPages
+	it.page++
+}
@@ -0,0 +1,57 @@
+package enigma
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestMetaQueryPage(t *testing.T) {
+	q := NewClient(key).Meta()
+	if q.params == nil {
+		t.Fatal("Meta() should initialize params")
+	}
+	paged := q.Limit(10).Page(2).Page(3)
+	if q.params.Encode() != "" {
+		t.Fatal("Page and Limit should not modify the original query", q.params)
+	}
+	if paged.params.Encode() != (url.Values{"limit": {"10"}, "page": {"3"}}).Encode() {
+		t.Fatal(paged.params.Encode())
+	}
+}
+
+func TestChildTableIterator(t *testing.T) {
+	server := newCatalogServer()
+	defer server.Close()
+	c := NewClient(key, WithBaseURL(server.URL))
+
+	var tables []string
+	it := c.Meta().ChildrenTables(context.Background(), "us.gov")
+	for it.Next() {
+		tables = append(tables, it.Table().Datapath)
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(tables, ","); got != "us.gov.a,us.gov.b,us.gov.c" {
+		t.Fatal(got)
+	}
+	if parent := it.Parent(); parent == nil || len(parent.Result.ImmediateNodes) != 1 {
+		t.Fatal("Parent should hold the first page", parent)
+	}
+
+	it = c.Meta().Page(2).ChildrenTables(context.Background(), "us.gov")
+	if !it.Next() || it.Table().Datapath != "us.gov.c" || it.Next() {
+		t.Fatal("Iteration should start from the page set on the query", it.Err())
+	}
+
+	it = c.Meta().ChildrenTables(context.Background(), "us")
+	if it.Next() || it.Err() != nil {
+		t.Fatal("Expected no tables", it.Err())
+	}
+	it = c.Meta().ChildrenTables(context.Background(), "nowhere")
+	if it.Next() || it.Err() == nil {
+		t.Fatal("Expected error was not returned")
+	}
+}
@@ -15,8 +15,9 @@
 package enigma
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 	"net/url"
@@ -31,8 +32,8 @@ const (
 )
 
 const (
-	pollingInterval = 10 * time.Second
-	pollingTimeout  = 2 * time.Minute
+	defaultPollingInterval = 10 * time.Second
+	defaultPollingTimeout  = 2 * time.Minute
 )
 
 type endpoint string
@@ -79,9 +80,52 @@ const (
 )
 
 type query struct {
+	client   *Client
 	baseURI  string
 	datapath string
 	params   url.Values
+	err      error // First error encountered while building the query, returned when it is sent.
+}
+
+// clone returns a deep copy of the query.
+func (q *query) clone() *query {
+	c := *q
+	c.params = copyValues(q.params)
+	return &c
+}
+
+// copyValues returns a deep copy of params.
+func copyValues(params url.Values) url.Values {
+	values := make(url.Values, len(params))
+	for k, v := range params {
+		values[k] = append([]string(nil), v...)
+	}
+	return values
+}
+
+// set returns a copy of the query where the given parameter is set to value, replacing
+// any previous value. Queries are never modified in place, which makes them safe for concurrent use.
+func (q *query) set(name, value string) *query {
+	c := q.clone()
+	c.params.Set(name, value)
+	return c
+}
+
+// add returns a copy of the query where value is added to the values of the given parameter.
+func (q *query) add(name, value string) *query {
+	c := q.clone()
+	c.params.Add(name, value)
+	return c
+}
+
+// fail returns a copy of the query recording err, unless an error was already recorded.
+// The first error encountered while building a query is returned when it is sent.
+func (q *query) fail(err error) *query {
+	c := q.clone()
+	if c.err == nil {
+		c.err = err
+	}
+	return c
 }
 
 // Although used in a single location, this function has been isolated to make the code
@@ -95,27 +139,24 @@ func buildURL(baseURI, datapath string, params url.Values) string {
 }
 
 // doQuery performs the actual HTTP request and parses the returned JSON into a typed response structure.
-func doQuery(baseURI, datapath string, params url.Values, response interface{}) (err error) {
+// The request is aborted as soon as ctx is canceled or its deadline expires.
+func (client *Client) doQuery(ctx context.Context, baseURI, datapath string, params url.Values, response interface{}) (err error) {
 	uri := buildURL(baseURI, datapath, params)
 
-	resp, err := http.Get(uri)
+	resp, err := client.send(ctx, http.MethodGet, uri)
 	if err != nil {
 		return
 	}
+	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return
 	}
-	defer resp.Body.Close()
 
 	// API error handling
 	if resp.StatusCode != 200 {
-		var e map[string]interface{}
-		if json.Unmarshal(body, &e) != nil {
-			return errors.New(resp.Status)
-		}
-		return errors.New(e["info"].(map[string]interface{})["additional"].(string))
+		return newAPIError(client.key, uri, resp, body)
 	}
 
 	// Parsing the response into the provided response struct.
@@ -126,6 +167,15 @@ func doQuery(baseURI, datapath string, params url.Values, response interface{})
 	return
 }
 
+// ChildTable describes a table listed in the metadata of a parent node.
+type ChildTable struct {
+	Datapath         string `json:"datapath"`
+	Label            string `json:"label"`
+	Description      string `json:"description"`
+	DbBoundaryLabel  string `json:"db_boundary_label"`
+	DbBoundaryTables string `json:"db_boundary_tables"`
+}
+
 // MetaParentNodeResponse represents the structure of a metadata response describing a parent node.
 type MetaParentNodeResponse struct {
 	DataPath string `json:"data_path"`
@@ -140,13 +190,7 @@ type MetaParentNodeResponse struct {
 			Label       string `json:"label"`
 			Description string `json:"description"`
 		} `json:"immediate_nodes"`
-		ChildrenTables []struct {
-			Datapath         string `json:"datapath"`
-			Label            string `json:"label"`
-			Description      string `json:"description"`
-			DbBoundaryLabel  string `json:"db_boundary_label"`
-			DbBoundaryTables string `json:"db_boundary_tables"`
-		} `json:"children_tables"`
+		ChildrenTables []ChildTable `json:"children_tables"`
 	} `json:"result"`
 	Info struct {
 		ResultType          string `json:"result_type"`
@@ -166,15 +210,9 @@ type MetaTableNodeResponse struct {
 			Label       string `json:"label"`
 			Description string `json:"description"`
 		} `json:"path"`
-		Columns []struct {
-			ID          string `json:"id"`
-			Label       string `json:"label"`
-			Description string `json:"description"`
-			Type        string `json:"type"`
-			Index       int    `json:"index"`
-		} `json:"columns"`
-		DbBoundaryDatapath string `json:"db_boundary_datapath"`
-		DbBoundaryLabel    string `json:"db_boundary_label"`
+		Columns            []Column `json:"columns"`
+		DbBoundaryDatapath string   `json:"db_boundary_datapath"`
+		DbBoundaryLabel    string   `json:"db_boundary_label"`
 		DbBoundaryTables   []struct {
 			Datapath string `json:"datapath"`
 			Label    string `json:"label"`
@@ -200,13 +238,34 @@ type MetaQuery query
 
 // Parent metadata request for the given datapath.
 func (q *MetaQuery) Parent(datapath string) (response *MetaParentNodeResponse, err error) {
-	err = doQuery(q.baseURI, datapath, q.params, &response)
+	return q.ParentContext(context.Background(), datapath)
+}
+
+// ParentContext is like Parent but aborts the request when ctx is done.
+func (q *MetaQuery) ParentContext(ctx context.Context, datapath string) (response *MetaParentNodeResponse, err error) {
+	err = q.client.doQuery(ctx, q.baseURI, datapath, q.params, &response)
 	return
 }
 
+// Limit the number of children tables returned per page by Parent.
+func (q *MetaQuery) Limit(number int) *MetaQuery {
+	return (*MetaQuery)((*query)(q).set("limit", strconv.Itoa(number)))
+}
+
+// Page paginates the children tables returned by Parent, and returns the nth page.
+// Pages are calculated based on the current limit, reported in Info.ChildrenTablesLimit.
+func (q *MetaQuery) Page(number int) *MetaQuery {
+	return (*MetaQuery)((*query)(q).set("page", strconv.Itoa(number)))
+}
+
 // Table metadata request for the given datapath.
 func (q *MetaQuery) Table(datapath string) (response *MetaTableNodeResponse, err error) {
-	err = doQuery(q.baseURI, datapath, q.params, &response)
+	return q.TableContext(context.Background(), datapath)
+}
+
+// TableContext is like Table but aborts the request when ctx is done.
+func (q *MetaQuery) TableContext(ctx context.Context, datapath string) (response *MetaTableNodeResponse, err error) {
+	err = q.client.doQuery(ctx, q.baseURI, datapath, q.params, &response)
 	return
 }
 
@@ -226,20 +285,29 @@ type StatsResponse struct {
 
 // StatsQuery can be used to query columns of tables for statistics on the data they contain.
 // Like data queries, stats queries may be filtered, sorted and paginated using the provided URL parameters.
+//
+// Queries are immutable: each method returns a new query and leaves its receiver untouched,
+// which makes it safe to share a query between goroutines, or to use it as a base for others.
+// Search() and Where() add to the parameters already set, while calling any other method
+// again replaces the value previously set.
 type StatsQuery query
 
+// Clone returns a copy of the query. As queries are immutable, it is only needed to get a
+// distinct pointer to an identical query.
+func (q *StatsQuery) Clone() *StatsQuery {
+	return (*StatsQuery)((*query)(q).clone())
+}
+
 // selectColumn sets the column to generate statistics for. Required.
 // Called directly from the Client.Stats as it's a mandatory field.
 func (q *StatsQuery) selectColumn(column string) *StatsQuery {
-	q.params.Add("select", column)
-	return q
+	return (*StatsQuery)((*query)(q).set("select", column))
 }
 
 // Limit the number of frequency, compound sum, or compound average results returned (max. 500).
 // Defaults to 500.
 func (q *StatsQuery) Limit(limit int) *StatsQuery {
-	q.params.Add("limit", strconv.Itoa(limit))
-	return q
+	return (*StatsQuery)((*query)(q).set("limit", strconv.Itoa(limit)))
 }
 
 // Search filters results by only returning rows that match a search query. Multiple search parameters may be provided.
@@ -248,9 +316,17 @@ func (q *StatsQuery) Limit(limit int) *StatsQuery {
 // To search particular fields only, use the StatsQuery format "@fieldname StatsQuery".
 //
 // To match multiple queries within a single search parameter, the | (or) operator can be used eg. "StatsQuery1|StatsQuery2".
-func (q *StatsQuery) Search(query string) *StatsQuery {
-	q.params.Add("search", query)
-	return q
+func (q *StatsQuery) Search(search string) *StatsQuery {
+	return (*StatsQuery)((*query)(q).add("search", search))
+}
+
+// SearchClause adds a search built with Match or Field, escaping the terms it holds.
+//    query.SearchClause(enigma.Field("namefull").Matches(userInput))
+func (q *StatsQuery) SearchClause(clause SearchClause) *StatsQuery {
+	if clause.err != nil {
+		return (*StatsQuery)((*query)(q).fail(clause.err))
+	}
+	return (*StatsQuery)((*query)(q).add("search", clause.String()))
 }
 
 // Where filters results with a SQL-style "where" clause.
@@ -265,15 +341,22 @@ func (q *StatsQuery) Search(query string) *StatsQuery {
 //
 // <column> [not] between <value> and <value>
 // Match rows where column lies within range provided (inclusive).
-func (q *StatsQuery) Where(query string) *StatsQuery {
-	q.params.Add("where", query)
-	return q
+func (q *StatsQuery) Where(where string) *StatsQuery {
+	return (*StatsQuery)((*query)(q).add("where", where))
+}
+
+// WhereClause adds a where clause built with Col. See Where() for the clauses supported by the API.
+//    query.WhereClause(enigma.Col("total_people").Gte(5))
+func (q *StatsQuery) WhereClause(clause Clause) *StatsQuery {
+	if clause.err != nil {
+		return (*StatsQuery)((*query)(q).fail(clause.err))
+	}
+	return (*StatsQuery)((*query)(q).add("where", clause.String()))
 }
 
 // Conjunction is only applicable when more than one Search() or Where() parameter is provided. Defaults to And.
 func (q *StatsQuery) Conjunction(conjunction Conjunction) *StatsQuery {
-	q.params.Add("conjunction", string(conjunction))
-	return q
+	return (*StatsQuery)((*query)(q).set("conjunction", string(conjunction)))
 }
 
 // Operation to run the given column.
@@ -286,8 +369,7 @@ func (q *StatsQuery) Conjunction(conjunction Conjunction) *StatsQuery {
 //
 // Defaults to all available operations based on the column's type.
 func (q *StatsQuery) Operation(operation Operation) *StatsQuery {
-	q.params.Add("operation", string(operation))
-	return q
+	return (*StatsQuery)((*query)(q).set("operation", string(operation)))
 }
 
 // By indicates the compound operation to run on a given pair of columns.
@@ -295,33 +377,37 @@ func (q *StatsQuery) Operation(operation Operation) *StatsQuery {
 //
 // When running a compound operation query, the Of() parameter is required (see below).
 func (q *StatsQuery) By(operation Operation) *StatsQuery {
-	q.params.Add("by", string(operation))
-	return q
+	return (*StatsQuery)((*query)(q).set("by", string(operation)))
 }
 
 // Of indicates the numerical column to compare against when running a compound operation.
 //
 // Required when using the By() parameter. Must be a numerical column.
 func (q *StatsQuery) Of(column string) *StatsQuery {
-	q.params.Add("of", column)
-	return q
+	return (*StatsQuery)((*query)(q).set("of", column))
 }
 
 // Sort rows by a particular column in a given direction. Asc denotes ascending order, Desc denotes descending.
 func (q *StatsQuery) Sort(direction SortDirection) *StatsQuery {
-	q.params.Add("sort", string(direction))
-	return q
+	return (*StatsQuery)((*query)(q).set("sort", string(direction)))
 }
 
 // Page paginates row results and returns the nth page of results. Pages are calculated based on the current limit, which defaults to 500.
 func (q *StatsQuery) Page(number int) *StatsQuery {
-	q.params.Add("page", strconv.Itoa(number))
-	return q
+	return (*StatsQuery)((*query)(q).set("page", strconv.Itoa(number)))
 }
 
 // Results or error returned by the server.
 func (q *StatsQuery) Results() (response *StatsResponse, err error) {
-	err = doQuery(q.baseURI, q.datapath, q.params, &response)
+	return q.ResultsContext(context.Background())
+}
+
+// ResultsContext is like Results but aborts the request when ctx is done.
+func (q *StatsQuery) ResultsContext(ctx context.Context) (response *StatsResponse, err error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	err = q.client.doQuery(ctx, q.baseURI, q.datapath, q.params, &response)
 	return
 }
 
@@ -339,18 +425,27 @@ type DataResponse struct {
 
 // DataQuery queries table datapaths for the data they contain.
 // Data queries may be filtered, sorted and paginated using the provided URL parameters.
+//
+// Queries are immutable: each method returns a new query and leaves its receiver untouched,
+// which makes it safe to share a query between goroutines, or to use it as a base for others.
+// Search() and Where() add to the parameters already set, while calling any other method
+// again replaces the value previously set.
 type DataQuery query
 
+// Clone returns a copy of the query. As queries are immutable, it is only needed to get a
+// distinct pointer to an identical query.
+func (q *DataQuery) Clone() *DataQuery {
+	return (*DataQuery)((*query)(q).clone())
+}
+
 // Limit the number of rows returned (max. 500). Defaults to 500.
 func (q *DataQuery) Limit(number int) *DataQuery {
-	q.params.Add("limit", strconv.Itoa(number))
-	return q
+	return (*DataQuery)((*query)(q).set("limit", strconv.Itoa(number)))
 }
 
 // Select the columns to be returned with each row. Default is to return all columns.
 func (q *DataQuery) Select(columns ...string) *DataQuery {
-	q.params.Add("select", strings.Join(columns, ","))
-	return q
+	return (*DataQuery)((*query)(q).set("select", strings.Join(columns, ",")))
 }
 
 // Search filters the results by only returning rows that match a query.
@@ -361,9 +456,17 @@ func (q *DataQuery) Select(columns ...string) *DataQuery {
 // To search particular fields only, use the query format "@fieldname query".
 //
 // To match multiple queries within a single search parameter, the | (or) operator can be used eg. "DataQuery1|DataQuery2".
-func (q *DataQuery) Search(query string) *DataQuery {
-	q.params.Add("search", query)
-	return q
+func (q *DataQuery) Search(search string) *DataQuery {
+	return (*DataQuery)((*query)(q).add("search", search))
+}
+
+// SearchClause adds a search built with Match or Field, escaping the terms it holds.
+//    query.SearchClause(enigma.Field("namefull").Matches(userInput))
+func (q *DataQuery) SearchClause(clause SearchClause) *DataQuery {
+	if clause.err != nil {
+		return (*DataQuery)((*query)(q).fail(clause.err))
+	}
+	return (*DataQuery)((*query)(q).add("search", clause.String()))
 }
 
 // Where filters results with a SQL-style "where" clause.
@@ -378,33 +481,46 @@ func (q *DataQuery) Search(query string) *DataQuery {
 //
 // <column> [not] between <value> and <value>
 // Match rows where column lies within range provided (inclusive).
-func (q *DataQuery) Where(query string) *DataQuery {
-	q.params.Add("where", query)
-	return q
+func (q *DataQuery) Where(where string) *DataQuery {
+	return (*DataQuery)((*query)(q).add("where", where))
+}
+
+// WhereClause adds a where clause built with Col. See Where() for the clauses supported by the API.
+//    query.WhereClause(enigma.Col("total_people").Gte(5))
+func (q *DataQuery) WhereClause(clause Clause) *DataQuery {
+	if clause.err != nil {
+		return (*DataQuery)((*query)(q).fail(clause.err))
+	}
+	return (*DataQuery)((*query)(q).add("where", clause.String()))
 }
 
 // Conjunction is only applicable when more than one Search() or Where() parameter is provided. Defaults to And.
 func (q *DataQuery) Conjunction(conjunction Conjunction) *DataQuery {
-	q.params.Add("conjunction", string(conjunction))
-	return q
+	return (*DataQuery)((*query)(q).set("conjunction", string(conjunction)))
 }
 
 // Sort rows by a particular column in a given direction.
 func (q *DataQuery) Sort(column string, direction SortDirection) *DataQuery {
-	q.params.Add("sort", column+string(direction))
-	return q
+	return (*DataQuery)((*query)(q).set("sort", column+string(direction)))
 }
 
 // Page paginates row results and return the nth page of results.
 // Pages are calculated based on the current limit, which defaults to 500.
 func (q *DataQuery) Page(number int) *DataQuery {
-	q.params.Add("page", strconv.Itoa(number))
-	return q
+	return (*DataQuery)((*query)(q).set("page", strconv.Itoa(number)))
 }
 
 // Results or error returned by the server.
 func (q *DataQuery) Results() (response DataResponse, err error) {
-	err = doQuery(q.baseURI, q.datapath, q.params, &response)
+	return q.ResultsContext(context.Background())
+}
+
+// ResultsContext is like Results but aborts the request when ctx is done.
+func (q *DataQuery) ResultsContext(ctx context.Context) (response DataResponse, err error) {
+	if q.err != nil {
+		return response, q.err
+	}
+	err = q.client.doQuery(ctx, q.baseURI, q.datapath, q.params, &response)
 	return
 }
 
@@ -416,12 +532,22 @@ type exportResponse struct {
 }
 
 // ExportQuery queries data tables to produce a file that can be downloaded.
+//
+// Queries are immutable: each method returns a new query and leaves its receiver untouched,
+// which makes it safe to share a query between goroutines, or to use it as a base for others.
+// Search() and Where() add to the parameters already set, while calling any other method
+// again replaces the value previously set.
 type ExportQuery query
 
+// Clone returns a copy of the query. As queries are immutable, it is only needed to get a
+// distinct pointer to an identical query.
+func (q *ExportQuery) Clone() *ExportQuery {
+	return (*ExportQuery)((*query)(q).clone())
+}
+
 // Select the list of columns to be returned with each row. Default is to return all columns.
 func (q *ExportQuery) Select(columns ...string) *ExportQuery {
-	q.params.Add("select", strings.Join(columns, ","))
-	return q
+	return (*ExportQuery)((*query)(q).set("select", strings.Join(columns, ",")))
 }
 
 // Search filters results by only returning rows that match a search query.
@@ -431,9 +557,17 @@ func (q *ExportQuery) Select(columns ...string) *ExportQuery {
 // To search particular fields only, use the DataQuery format "@fieldname DataQuery".
 //
 // To match multiple queries within a single search parameter, the | (or) operator can be used eg. "query1|query2".
-func (q *ExportQuery) Search(query string) *ExportQuery {
-	q.params.Add("search", query)
-	return q
+func (q *ExportQuery) Search(search string) *ExportQuery {
+	return (*ExportQuery)((*query)(q).add("search", search))
+}
+
+// SearchClause adds a search built with Match or Field, escaping the terms it holds.
+//    query.SearchClause(enigma.Field("namefull").Matches(userInput))
+func (q *ExportQuery) SearchClause(clause SearchClause) *ExportQuery {
+	if clause.err != nil {
+		return (*ExportQuery)((*query)(q).fail(clause.err))
+	}
+	return (*ExportQuery)((*query)(q).add("search", clause.String()))
 }
 
 // Where filters results with a SQL-style "where" clause.
@@ -448,33 +582,40 @@ func (q *ExportQuery) Search(query string) *ExportQuery {
 //
 // <column> [not] between <value> and <value>
 // Match rows where column lies within range provided (inclusive).
-func (q *ExportQuery) Where(query string) *ExportQuery {
-	q.params.Add("where", query)
-	return q
+func (q *ExportQuery) Where(where string) *ExportQuery {
+	return (*ExportQuery)((*query)(q).add("where", where))
+}
+
+// WhereClause adds a where clause built with Col. See Where() for the clauses supported by the API.
+//    query.WhereClause(enigma.Col("total_people").Gte(5))
+func (q *ExportQuery) WhereClause(clause Clause) *ExportQuery {
+	if clause.err != nil {
+		return (*ExportQuery)((*query)(q).fail(clause.err))
+	}
+	return (*ExportQuery)((*query)(q).add("where", clause.String()))
 }
 
 // Conjunction is only applicable when more than one Search() or Where() parameter is provided. Defaults to And.
 func (q *ExportQuery) Conjunction(conjunction Conjunction) *ExportQuery {
-	q.params.Add("conjunction", string(conjunction))
-	return q
+	return (*ExportQuery)((*query)(q).set("conjunction", string(conjunction)))
 }
 
 // Sort rows by a particular column in a given direction. Asc denotes ascending order, Desc denotes descending.
 func (q *ExportQuery) Sort(column string, direction SortDirection) *ExportQuery {
-	q.params.Add("sort", column+string(direction))
-	return q
+	return (*ExportQuery)((*query)(q).set("sort", column+string(direction)))
 }
 
 // Page paginates row results and returns the nth page of results. Pages are calculated based on the current limit, which defaults to 500.
 func (q *ExportQuery) Page(number int) *ExportQuery {
-	q.params.Add("page", strconv.Itoa(number))
-	return q
+	return (*ExportQuery)((*query)(q).set("page", strconv.Itoa(number)))
 }
 
 // FileURL returns the URL of the GZip file containing the exported data.
 //
 // Passing the ready chan will poll the returned URL until the  file is ready
 // for take out. The url pushed down the channel should be used to download the file.
+// Nothing is sent if the file is not ready before the polling timeout. Use Start
+// instead to be notified of failures.
 //
 // Passing nil will simply return the url of the file to download.
 //
@@ -486,39 +627,156 @@ func (q *ExportQuery) Page(number int) *ExportQuery {
 // 	}
 // 	downloadUrl := <- ready
 func (q *ExportQuery) FileURL(ready chan string) (url string, err error) {
-	var response exportResponse
-	err = doQuery(q.baseURI, q.datapath, q.params, &response)
-
-	if ready != nil {
-		go func(pollingURL, downloadURL string) {
-			for interval := pollingInterval; interval < pollingTimeout; interval = interval * 2 {
-				if resp, err := http.Head(pollingURL); err == nil && resp.StatusCode == 200 {
-					ready <- downloadURL
-					break
-				}
-				time.Sleep(interval)
+	return q.FileURLContext(context.Background(), ready)
+}
+
+// FileURLContext is like FileURL but aborts the export request when ctx is done.
+// Canceling ctx also stops polling, in which case nothing is sent on ready.
+func (q *ExportQuery) FileURLContext(ctx context.Context, ready chan string) (url string, err error) {
+	if ready == nil {
+		response, err := q.export(ctx)
+		return response.ExportURL, err
+	}
+
+	job, err := q.Start(ctx)
+	if err != nil {
+		return "", err
+	}
+	go func() {
+		if url, err := job.Wait(ctx); err == nil {
+			select {
+			case ready <- url:
+			case <-ctx.Done():
 			}
-		}(response.HeadURL, response.ExportURL)
+		}
+	}()
+	return job.URL(), nil
+}
+
+// export requests the export of the table.
+func (q *ExportQuery) export(ctx context.Context) (response exportResponse, err error) {
+	if q.err != nil {
+		return response, q.err
+	}
+	err = q.client.doQuery(ctx, q.baseURI, q.datapath, q.params, &response)
+	return
+}
+
+// isReady sends a HEAD request to the polling URL of an export and reports
+// whether the exported file can be downloaded. The file is not ready yet while the URL
+// responds with 404 Not Found, or fails with a transient error or status, as defined by RetryPolicy.
+// Any other status is returned as an *APIError, and other errors as is.
+//
+// The request is retried according to policy, which can be nil.
+func (client *Client) isReady(ctx context.Context, pollingURL string, policy *RetryPolicy) (bool, error) {
+	resp, err := client.sendWith(ctx, client.httpClient, policy, http.MethodHead, pollingURL, nil, true)
+	if err != nil {
+		if ctx.Err() != nil || transient(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	defer resp.Body.Close()
+	switch {
+	case resp.StatusCode == http.StatusOK:
+		return true, nil
+	case resp.StatusCode == http.StatusNotFound, retryable(ctx, resp, nil):
+		return false, nil
 	}
-	return response.ExportURL, err
+	body, _ := ioutil.ReadAll(resp.Body)
+	return false, newAPIError(client.key, pollingURL, resp, body)
 }
 
 // Client of the Enigma API.
 // Use NewClient to instantiate a new instance as in the following example:
 //    client := enigma.NewClient("some_api_key")
 type Client struct {
-	key string
+	key        string
+	root       string
+	version    string
+	httpClient *http.Client
+	fileClient *http.Client // Same as httpClient, without time limit.
+	timeout    time.Duration
+	userAgent  string
+	retry      *RetryPolicy
+	limiter    *limiter
+	tables     tableCache
+
+	pollingInterval time.Duration
+	pollingTimeout  time.Duration
+}
+
+// send performs an HTTP request through the configured http.Client,
+// retrying it according to the retry policy of the client.
+// Each attempt waits for the rate limit and concurrency cap of the client.
+//
+// The body of the response is read by each attempt, so that responses interrupted
+// mid-way are retried as well.
+func (client *Client) send(ctx context.Context, method, uri string) (*http.Response, error) {
+	return client.sendWith(ctx, client.httpClient, client.retry, method, uri, nil, true)
+}
+
+// sendFile is like send for the GET request of an exported file, with additional headers.
+// Reading a file may take longer than the time limit set with WithTimeout, which is not applied,
+// and files are not counted by the concurrency cap set with WithMaxInFlight.
+func (client *Client) sendFile(ctx context.Context, uri string, header http.Header) (*http.Response, error) {
+	return client.sendWith(ctx, client.fileClient, client.retry, http.MethodGet, uri, header, false)
+}
+
+// sendWith performs a request through httpClient, retried according to policy, with additional
+// headers set on the request. When buffered is true, the body of the response is read before
+// the attempt ends.
+func (client *Client) sendWith(ctx context.Context, httpClient *http.Client, policy *RetryPolicy, method, uri string, header http.Header, buffered bool) (*http.Response, error) {
+	return client.withRetries(ctx, policy, uri, func() (*http.Response, error) {
+		req, err := http.NewRequestWithContext(ctx, method, uri, nil)
+		if err != nil {
+			return nil, redactError(client.key, err)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+		if client.userAgent != "" {
+			req.Header.Set("User-Agent", client.userAgent)
+		}
+
+		// Only buffered requests are sure to free their slot promptly: exported files, which are
+		// streamed for as long as the caller reads them, are not counted by the concurrency cap.
+		release, err := client.limiter.wait(ctx, buffered)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			release()
+			return nil, redactError(client.key, err)
+		}
+		if !buffered {
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: release}
+			return resp, nil
+		}
+
+		defer release()
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return resp, nil
+	})
 }
 
 // buildURI assembles the URI tho which queries should be sent.
 func (client *Client) buildURI(ep endpoint) string {
 	//<root>/<version>/<endpoint>/<api key>/<datapath>/<parameters>
-	return strings.Join([]string{root, version, string(ep), client.key}, "/")
+	return strings.Join([]string{client.root, client.version, string(ep), client.key}, "/")
 }
 
 // Meta can be used to query all datapaths for their metadata.
 func (client *Client) Meta() *MetaQuery {
 	return &MetaQuery{
+		client:  client,
+		params:  url.Values{},
 		baseURI: client.buildURI(meta),
 	}
 }
@@ -533,6 +791,7 @@ func (client *Client) Meta() *MetaQuery {
 //    client.Data("us.gov.whitehouse.visitor-list").Select("namefull", "appt_made_date").Sort("namefirst", enigma.Desc).Results()
 func (client *Client) Data(datapath string) *DataQuery {
 	return &DataQuery{
+		client:   client,
 		datapath: datapath,
 		params:   url.Values{},
 		baseURI:  client.buildURI(data),
@@ -546,6 +805,7 @@ func (client *Client) Data(datapath string) *DataQuery {
 //    client.Stats("us.gov.whitehouse.visitor-list", "total_people").Operation(enigma.Sum).Results()
 func (client *Client) Stats(datapath, column string) *StatsQuery {
 	q := &StatsQuery{
+		client:   client,
 		datapath: datapath,
 		params:   url.Values{},
 		baseURI:  client.buildURI(stats),
@@ -560,6 +820,7 @@ func (client *Client) Stats(datapath, column string) *StatsQuery {
 //    client.Export("us.gov.whitehouse.visitor-list").Select("namefull").Sort("namefull", Asc).FileURL(nil)
 func (client *Client) Export(datapath string) *ExportQuery {
 	return &ExportQuery{
+		client:   client,
 		datapath: datapath,
 		params:   url.Values{},
 		baseURI:  client.buildURI(export),
@@ -567,8 +828,31 @@ func (client *Client) Export(datapath string) *ExportQuery {
 }
 
 // NewClient instantiates a new Client instance with a given API key.
-func NewClient(key string) *Client {
-	return &Client{
-		key: key,
+// Options can be provided to customize the way requests are sent:
+//    client := enigma.NewClient("some_api_key", enigma.WithTimeout(30*time.Second))
+func NewClient(key string, options ...Option) *Client {
+	client := &Client{
+		key:        key,
+		root:       root,
+		version:    version,
+		httpClient: http.DefaultClient,
+		limiter:    &limiter{},
+
+		pollingInterval: defaultPollingInterval,
+		pollingTimeout:  defaultPollingTimeout,
+	}
+	for _, option := range options {
+		option(client)
+	}
+	// Exported files are only limited by the context of their download.
+	fileClient := *client.httpClient
+	fileClient.Timeout = 0
+	client.fileClient = &fileClient
+	if client.timeout > 0 {
+		// Copied to avoid altering an http.Client shared with the rest of the program.
+		httpClient := *client.httpClient
+		httpClient.Timeout = client.timeout
+		client.httpClient = &httpClient
 	}
+	return client
 }
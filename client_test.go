@@ -1,9 +1,13 @@
 package enigma
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 
 	enigma "."
 )
@@ -45,22 +49,27 @@ func Example_stats() {
 		return
 	}
 
-	var obj map[string]string
-	json.Unmarshal(response.Result, &obj)
-	fmt.Println(obj["sum"])
+	stats, err := response.Decode()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(stats.Numeric.Sum)
 }
 
 func Example_export() {
-	ready := make(chan string)
-
 	client := enigma.NewClient("some_api_key")
-	_, err := client.Export("us.gov.whitehouse.visitor-list").FileURL(ready)
+	job, err := client.Export("us.gov.whitehouse.visitor-list").Start(context.Background())
 	if err != nil {
 		fmt.Println(err)
 		return
 	}
 
-	url := <-ready
+	url, err := job.Wait(context.Background())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
 	fmt.Println(url)
 	// url now points to a ready to download file.
 }
@@ -273,3 +282,132 @@ func TestExportQueryWhere(t *testing.T) {
 		t.Fatal("Parameter was not properly added to the query")
 	}
 }
+
+func TestDataQueryResultsContext(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	query := NewClient(key, WithBaseURL(server.URL)).Data(datapath)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := query.ResultsContext(ctx); err == nil {
+		t.Fatal("Expected the request to be aborted by the context")
+	}
+}
+
+func TestExportQueryFileURLContext(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"export_url": "%s/file.gz", "head_url": "%s/head"}`, server.URL, server.URL)
+	}))
+	defer server.Close()
+
+	ready := make(chan string)
+	query := NewClient(key, WithBaseURL(server.URL)).Export(datapath)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fileURL, err := query.FileURLContext(ctx, ready)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fileURL != server.URL+"/file.gz" {
+		t.Fatal(fileURL)
+	}
+
+	cancel()
+	select {
+	case <-ready:
+		t.Fatal("No URL should be sent once the context is canceled")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSingleValuedParameters(t *testing.T) {
+	data := client.Data(datapath).Page(2).Page(3).Limit(10).Limit(20).Select("a").Select("b").Sort("a", Asc).Sort("b", Desc).Conjunction(And).Conjunction(Or)
+	for name, expected := range map[string]string{"page": "3", "limit": "20", "select": "b", "sort": "b-", "conjunction": "or"} {
+		if values := data.params[name]; len(values) != 1 || values[0] != expected {
+			t.Fatal(name, values)
+		}
+	}
+
+	stats := client.Stats(datapath, "column").Operation(Sum).Operation(Avg).Page(1).Page(2).Sort(Asc).Sort(Desc)
+	for name, expected := range map[string]string{"operation": "avg", "page": "2", "sort": "-", "select": "column"} {
+		if values := stats.params[name]; len(values) != 1 || values[0] != expected {
+			t.Fatal(name, values)
+		}
+	}
+
+	export := client.Export(datapath).Page(1).Page(2).Search("a").Search("b").Where("x>1").Where("x<3")
+	if len(export.params["page"]) != 1 || len(export.params["search"]) != 2 || len(export.params["where"]) != 2 {
+		t.Fatal(export.params)
+	}
+}
+
+func TestQueryClone(t *testing.T) {
+	base := client.Data(datapath).Select("namefull").Where("total_people>1")
+	clone := base.Clone().Page(2).Where("total_people<5")
+	if base.params.Get("page") != "" || len(base.params["where"]) != 1 {
+		t.Fatal("Modifying a clone altered the original query", base.params)
+	}
+	if clone.params.Get("select") != "namefull" || len(clone.params["where"]) != 2 {
+		t.Fatal("Clone did not inherit the parameters of the original query", clone.params)
+	}
+
+	stats := client.Stats(datapath, "column")
+	if stats.Clone().Operation(Sum); stats.params.Get("operation") != "" {
+		t.Fatal("Modifying a clone altered the original query")
+	}
+	export := client.Export(datapath)
+	if export.Clone().Select("x"); export.params.Get("select") != "" {
+		t.Fatal("Modifying a clone altered the original query")
+	}
+}
+
+func TestQueryImmutability(t *testing.T) {
+	base := client.Data(datapath)
+	base.Limit(10).Page(2).Where("x>1").Search("a").WhereClause(Col("").Eq(1))
+	if len(base.params) != 0 || base.err != nil {
+		t.Fatal("Builder methods should not modify their receiver", base.params, base.err)
+	}
+
+	stats := client.Stats(datapath, "column")
+	stats.Operation(Sum).By(Avg).Of("x")
+	if len(stats.params) != 1 {
+		t.Fatal("Builder methods should not modify their receiver", stats.params)
+	}
+}
+
+func TestConcurrentPagination(t *testing.T) {
+	server, _ := newPagedServer(20)
+	defer server.Close()
+
+	base := NewClient(key, WithBaseURL(server.URL)).Data(datapath).Select("n").Limit(2)
+	var wg sync.WaitGroup
+	for page := 1; page <= 20; page++ {
+		wg.Add(1)
+		go func(page int) {
+			defer wg.Done()
+			query := base.Page(page)
+			response, err := query.Results()
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if response.Info.CurrentPage != page || len(query.params["page"]) != 1 {
+				t.Error("Unexpected page", response.Info.CurrentPage, "instead of", page)
+			}
+		}(page)
+	}
+	wg.Wait()
+
+	if base.params.Get("page") != "" {
+		t.Fatal("Shared query was modified")
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/mohamedattahri/enigma"
+)
+
+// initialisms are written in upper case in generated identifiers, as recommended by Go conventions.
+var initialisms = map[string]bool{
+	"api": true, "id": true, "ip": true, "url": true, "uri": true, "uuid": true, "zip": true,
+}
+
+// identifier turns a column ID or a datapath element (e.g. "appt_made_date") into an exported
+// Go identifier (e.g. "ApptMadeDate"). It returns an empty string if name holds no letter nor digit.
+func identifier(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, word := range words {
+		word = strings.ToLower(word)
+		if initialisms[word] {
+			b.WriteString(strings.ToUpper(word))
+			continue
+		}
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		b.WriteString(string(runes))
+	}
+
+	id := b.String()
+	if id != "" && !unicode.IsLetter([]rune(id)[0]) {
+		id = "X" + id
+	}
+	return id
+}
+
+// typeNameFor returns the default name of the struct generated for a datapath,
+// derived from its last element.
+func typeNameFor(datapath string) string {
+	name := identifier(datapath[strings.LastIndex(datapath, ".")+1:])
+	if name == "" {
+		return "Row"
+	}
+	return name
+}
+
+// goType returns the type of the field holding the values of the given column.
+func goType(c enigma.Column) string {
+	switch c.Kind() {
+	case enigma.NumericColumn:
+		return "float64"
+	case enigma.DateColumn:
+		return "time.Time"
+	}
+	return "string"
+}
+
+// comment writes text as a comment, one line of comment per line of text.
+func comment(w io.Writer, indent, text string) {
+	for _, line := range strings.Split(strings.TrimSpace(text), "\n") {
+		if line = strings.TrimSpace(line); line == "" {
+			fmt.Fprintf(w, "%s//\n", indent)
+			continue
+		}
+		fmt.Fprintf(w, "%s// %s\n", indent, line)
+	}
+}
+
+// generate writes a Go file declaring a struct named typeName mirroring the given columns,
+// and a constant for the ID of each column.
+func generate(w io.Writer, pkg, typeName, datapath string, columns []enigma.Column) error {
+	names := make([]string, len(columns))
+	used := map[string]bool{}
+	hasDate := false
+	for i, c := range columns {
+		name := identifier(c.ID)
+		if name == "" {
+			name = "Column" + strconv.Itoa(i)
+		}
+		for base, n := name, 2; used[name]; n++ {
+			name = base + strconv.Itoa(n)
+		}
+		used[name] = true
+		names[i] = name
+		hasDate = hasDate || c.Kind() == enigma.DateColumn
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by enigma-gen from %s; DO NOT EDIT.\n\n", datapath)
+	fmt.Fprintf(&buf, "package %s\n\n", pkg)
+	if hasDate {
+		fmt.Fprintf(&buf, "import \"time\"\n\n")
+	}
+
+	fmt.Fprintf(&buf, "// %s is a row of the %s table, to be decoded with enigma.RowDecoder.\n", typeName, datapath)
+	fmt.Fprintf(&buf, "type %s struct {\n", typeName)
+	for i, c := range columns {
+		label := c.Label
+		if label == "" {
+			label = c.ID
+		}
+		comment(&buf, "\t", fmt.Sprintf("%s holds the %q column (%s).", names[i], label, c.Type))
+		if c.Description != "" {
+			fmt.Fprintf(&buf, "\t//\n")
+			comment(&buf, "\t", c.Description)
+		}
+		fmt.Fprintf(&buf, "\t%s %s `enigma:%q`\n", names[i], goType(c), c.ID)
+	}
+	fmt.Fprintf(&buf, "}\n\n")
+
+	fmt.Fprintf(&buf, "// IDs of the columns of the %s table, to be used with Select, Sort and Where.\n", datapath)
+	fmt.Fprintf(&buf, "const (\n")
+	for i, c := range columns {
+		fmt.Fprintf(&buf, "\t%s%s = %q\n", typeName, names[i], c.ID)
+	}
+	fmt.Fprintf(&buf, ")\n")
+
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(src)
+	return err
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/mohamedattahri/enigma"
+)
+
+func TestIdentifier(t *testing.T) {
+	identifiers := map[string]string{
+		"namefull":       "Namefull",
+		"appt_made_date": "ApptMadeDate",
+		"visitor-list":   "VisitorList",
+		"uin_id":         "UinID",
+		"2012_total":     "X2012Total",
+		"__":             "",
+	}
+	for name, expected := range identifiers {
+		if id := identifier(name); id != expected {
+			t.Fatal(name, id)
+		}
+	}
+}
+
+func TestTypeNameFor(t *testing.T) {
+	if name := typeNameFor("us.gov.whitehouse.visitor-list"); name != "VisitorList" {
+		t.Fatal(name)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	columns := []enigma.Column{
+		{ID: "namefull", Label: "Full Name", Type: "type_varchar", Description: "Name of the visitor.\n\nAs registered."},
+		{ID: "total_people", Label: "Total People", Type: "type_numeric"},
+		{ID: "appt_made_date", Label: "Appointment Made Date", Type: "type_date"},
+		{ID: "total-people", Type: "type_numeric"},
+	}
+
+	var buf bytes.Buffer
+	if err := generate(&buf, "visits", "VisitorList", "us.gov.whitehouse.visitor-list", columns); err != nil {
+		t.Fatal(err)
+	}
+	src := buf.String()
+
+	if _, err := parser.ParseFile(token.NewFileSet(), "visitor_list.go", src, parser.ParseComments); err != nil {
+		t.Fatal(err, src)
+	}
+	for _, expected := range []string{
+		"package visits",
+		`import "time"`,
+		"Namefull string `enigma:\"namefull\"`",
+		"TotalPeople float64 `enigma:\"total_people\"`",
+		"TotalPeople2 float64 `enigma:\"total-people\"`",
+		"ApptMadeDate time.Time `enigma:\"appt_made_date\"`",
+		"// Name of the visitor.\n\t//\n\t// As registered.",
+		`VisitorListNamefull     = "namefull"`,
+	} {
+		if !strings.Contains(src, expected) {
+			t.Fatalf("%q is missing from:\n%s", expected, src)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Command enigma-gen generates the Go declarations needed to work with an Enigma table
+// in a type-safe way, out of the metadata returned by MetaQuery.Table:
+//
+// - a struct whose fields mirror the columns of the table, ready to be used with enigma.RowDecoder;
+//
+// - a constant for each column ID, to be used with Select, Sort and Where instead of string literals.
+//
+// Usage:
+//    enigma-gen -key some_api_key -datapath us.gov.whitehouse.visitor-list -package visits -o visitor_list.go
+//
+// The API key can also be provided through the ENIGMA_API_KEY environment variable.
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/mohamedattahri/enigma"
+)
+
+func main() {
+	var (
+		key      = flag.String("key", os.Getenv("ENIGMA_API_KEY"), "Enigma API key (defaults to $ENIGMA_API_KEY)")
+		datapath = flag.String("datapath", "", "datapath of the table, e.g. us.gov.whitehouse.visitor-list (required)")
+		pkg      = flag.String("package", "main", "name of the package of the generated file")
+		typeName = flag.String("type", "", "name of the generated struct (defaults to the last element of the datapath)")
+		output   = flag.String("o", "", "output file (defaults to stdout)")
+		baseURL  = flag.String("base-url", "", "root URL of the API (defaults to https://api.enigma.io)")
+		timeout  = flag.Duration("timeout", time.Minute, "time limit of the metadata request")
+	)
+	flag.Parse()
+
+	if *key == "" || *datapath == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *typeName == "" {
+		*typeName = typeNameFor(*datapath)
+	}
+
+	options := []enigma.Option{enigma.WithTimeout(*timeout)}
+	if *baseURL != "" {
+		options = append(options, enigma.WithBaseURL(*baseURL))
+	}
+	client := enigma.NewClient(*key, options...)
+
+	table, err := client.Meta().TableContext(context.Background(), *datapath)
+	if err != nil {
+		fail(err)
+	}
+
+	var buf bytes.Buffer
+	if err := generate(&buf, *pkg, *typeName, *datapath, table.Result.Columns); err != nil {
+		fail(err)
+	}
+
+	if *output == "" {
+		os.Stdout.Write(buf.Bytes())
+		return
+	}
+	if err := ioutil.WriteFile(*output, buf.Bytes(), 0644); err != nil {
+		fail(err)
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "enigma-gen:", err)
+	os.Exit(1)
+}
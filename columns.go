@@ -0,0 +1,76 @@
+package enigma
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Layouts of the dates and times handled by the API.
+const (
+	DateFormat     = "2006-01-02"
+	DateTimeFormat = "2006-01-02T15:04:05"
+)
+
+// dateLayouts lists the layouts under which the API may encode dates, most precise first.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	DateTimeFormat,
+	"2006-01-02 15:04:05",
+	DateFormat,
+}
+
+// parseDate parses a date encoded by the API.
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, errors.New("enigma: invalid date " + value)
+}
+
+// ColumnKind groups the types of columns according to the operations they support.
+type ColumnKind int
+
+// Kinds of columns
+const (
+	// StringColumn columns only support search and frequency operations.
+	StringColumn ColumnKind = iota
+	// NumericColumn columns support where clauses and all stats operations.
+	NumericColumn
+	// DateColumn columns support where clauses and the min, max and frequency operations.
+	DateColumn
+)
+
+func (k ColumnKind) String() string {
+	switch k {
+	case NumericColumn:
+		return "numeric"
+	case DateColumn:
+		return "date"
+	}
+	return "string"
+}
+
+// Column describes a column of a table, as returned by MetaQuery.Table.
+type Column struct {
+	ID          string `json:"id"`
+	Label       string `json:"label"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Index       int    `json:"index"`
+}
+
+// Kind of the values stored in the column, derived from its Type (e.g. "type_numeric").
+func (c Column) Kind() ColumnKind {
+	t := strings.TrimPrefix(strings.ToLower(c.Type), "type_")
+	switch t {
+	case "numeric", "decimal", "float", "double", "real", "number", "int", "integer", "bigint", "smallint":
+		return NumericColumn
+	}
+	if strings.HasPrefix(t, "date") || strings.HasPrefix(t, "time") {
+		return DateColumn
+	}
+	return StringColumn
+}
@@ -0,0 +1,367 @@
+package enigma
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	timeType            = reflect.TypeOf(time.Time{})
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// RowDecoder decodes the rows returned by the API into Go values.
+//
+// Rows can be decoded into structs, whose fields are mapped to columns using the enigma tag,
+// or by a case-insensitive match of their name. Fields tagged with "-" are ignored.
+//    type Visit struct {
+//        Name   string    `enigma:"namefull"`
+//        Date   time.Time `enigma:"appt_made_date"`
+//        People int64     `enigma:"total_people"`
+//    }
+//
+// Values, which the API encodes as strings, are converted to the type of the field they are
+// stored in: string, bool, integers, floats, time.Time, types implementing encoding.TextUnmarshaler,
+// or pointers to any of them, which are left nil when a value is null.
+//
+// Rows can also be decoded into maps of type map[string]interface{}, in which case values are
+// converted according to the type of their column: int64 or float64 for numeric columns,
+// time.Time for date columns, and string for all others.
+type RowDecoder struct {
+	kinds map[string]ColumnKind
+}
+
+// NewRowDecoder returns a RowDecoder converting values according to the given columns,
+// usually those returned by MetaQuery.Table.
+func NewRowDecoder(columns []Column) *RowDecoder {
+	d := &RowDecoder{kinds: make(map[string]ColumnKind, len(columns))}
+	for _, c := range columns {
+		d.kinds[c.ID] = c.Kind()
+	}
+	return d
+}
+
+// Decoder returns a RowDecoder for the columns of the table queried.
+// The metadata of the table is fetched on first use, and cached by the client.
+func (q *DataQuery) Decoder(ctx context.Context) (*RowDecoder, error) {
+	table, err := q.client.table(ctx, q.datapath)
+	if err != nil {
+		return nil, err
+	}
+	return NewRowDecoder(table.Result.Columns), nil
+}
+
+// DecodeRows decodes the Result of a DataResponse into v, which must be a pointer
+// to a slice of structs, maps, or pointers to either.
+//    var visits []Visit
+//    err := decoder.DecodeRows(response.Result, &visits)
+func (d *RowDecoder) DecodeRows(result json.RawMessage, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Slice {
+		return errors.New("enigma: DecodeRows expects a non-nil pointer to a slice")
+	}
+
+	var rows []json.RawMessage
+	if err := json.Unmarshal(result, &rows); err != nil {
+		return err
+	}
+
+	slice := reflect.MakeSlice(rv.Elem().Type(), len(rows), len(rows))
+	for i, row := range rows {
+		record, err := parseRow(row)
+		if err != nil {
+			return err
+		}
+		if err := d.decodeRecord(record, slice.Index(i)); err != nil {
+			return err
+		}
+	}
+	rv.Elem().Set(slice)
+	return nil
+}
+
+// DecodeRow decodes a single row, such as those returned by DataIterator.Row, into v,
+// which must be a pointer to a struct or a map.
+func (d *RowDecoder) DecodeRow(row json.RawMessage, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("enigma: DecodeRow expects a non-nil pointer")
+	}
+	record, err := parseRow(row)
+	if err != nil {
+		return err
+	}
+	return d.decodeRecord(record, rv.Elem())
+}
+
+// parseRow turns a JSON row into a record mapping column IDs to their string-encoded values.
+// Null values are nil.
+func parseRow(row json.RawMessage) (map[string]*string, error) {
+	dec := json.NewDecoder(bytes.NewReader(row))
+	dec.UseNumber()
+
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return nil, err
+	}
+
+	record := make(map[string]*string, len(fields))
+	for column, value := range fields {
+		var s string
+		switch value := value.(type) {
+		case nil:
+			record[column] = nil
+			continue
+		case string:
+			s = value
+		case json.Number:
+			s = value.String()
+		case bool:
+			s = strconv.FormatBool(value)
+		default:
+			b, err := json.Marshal(value)
+			if err != nil {
+				return nil, err
+			}
+			s = string(b)
+		}
+		record[column] = &s
+	}
+	return record, nil
+}
+
+// kind returns the kind of the given column. Unknown columns are treated as strings.
+func (d *RowDecoder) kind(column string) ColumnKind {
+	if d == nil {
+		return StringColumn
+	}
+	return d.kinds[column]
+}
+
+// decodeRecord stores the values of record into dst, a struct, a map, or a pointer to either.
+func (d *RowDecoder) decodeRecord(record map[string]*string, dst reflect.Value) error {
+	if dst.Kind() == reflect.Ptr {
+		if dst.IsNil() {
+			dst.Set(reflect.New(dst.Type().Elem()))
+		}
+		dst = dst.Elem()
+	}
+
+	switch dst.Kind() {
+	case reflect.Struct:
+		for _, f := range structFields(dst.Type()) {
+			column, value, ok := lookup(record, f)
+			if !ok {
+				continue
+			}
+			if err := d.decodeValue(column, value, dst.Field(f.index)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		if dst.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if dst.IsNil() {
+			dst.Set(reflect.MakeMapWithSize(dst.Type(), len(record)))
+		}
+		for column, value := range record {
+			elem := reflect.New(dst.Type().Elem()).Elem()
+			if err := d.decodeValue(column, value, elem); err != nil {
+				return err
+			}
+			dst.SetMapIndex(reflect.ValueOf(column).Convert(dst.Type().Key()), elem)
+		}
+		return nil
+	}
+	return fmt.Errorf("enigma: cannot decode a row into %s", dst.Type())
+}
+
+// decodeValue converts the string-encoded value of a column and stores it into dst.
+func (d *RowDecoder) decodeValue(column string, value *string, dst reflect.Value) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("enigma: cannot decode column %q into %s: %v", column, dst.Type(), err)
+		}
+	}()
+
+	if value == nil {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+	s := *value
+
+	if dst.Kind() == reflect.Ptr {
+		if s == "" && dst.Type().Elem().Kind() != reflect.String {
+			dst.Set(reflect.Zero(dst.Type()))
+			return nil
+		}
+		elem := reflect.New(dst.Type().Elem())
+		if err := d.decodeValue(column, value, elem.Elem()); err != nil {
+			return err
+		}
+		dst.Set(elem)
+		return nil
+	}
+
+	if dst.Type() == timeType {
+		if s == "" {
+			dst.Set(reflect.Zero(timeType))
+			return nil
+		}
+		t, err := parseDate(s)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	if dst.CanAddr() && dst.Addr().Type().Implements(textUnmarshalerType) {
+		return dst.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s))
+	}
+
+	if s == "" && dst.Kind() != reflect.String && dst.Kind() != reflect.Interface {
+		dst.Set(reflect.Zero(dst.Type()))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := parseInt(s, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+	case reflect.Interface:
+		if dst.NumMethod() > 0 {
+			return errors.New("unsupported type")
+		}
+		v, err := convert(d.kind(column), s)
+		if err != nil {
+			return err
+		}
+		if v == nil {
+			dst.Set(reflect.Zero(dst.Type()))
+		} else {
+			dst.Set(reflect.ValueOf(v))
+		}
+	default:
+		return errors.New("unsupported type")
+	}
+	return nil
+}
+
+// parseInt parses an integer, accepting integral values written as decimals (e.g. "5.0").
+func parseInt(s string, bits int) (int64, error) {
+	n, err := strconv.ParseInt(s, 10, bits)
+	if err == nil {
+		return n, nil
+	}
+	f, ferr := strconv.ParseFloat(s, 64)
+	if ferr != nil || f != float64(int64(f)) {
+		return 0, err
+	}
+	return strconv.ParseInt(strconv.FormatInt(int64(f), 10), 10, bits)
+}
+
+// convert returns the value of a column of the given kind as an int64 or float64, a time.Time, or a string.
+// Empty numeric and date values are nil.
+func convert(kind ColumnKind, s string) (interface{}, error) {
+	switch kind {
+	case NumericColumn:
+		if s == "" {
+			return nil, nil
+		}
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n, nil
+		}
+		return strconv.ParseFloat(s, 64)
+	case DateColumn:
+		if s == "" {
+			return nil, nil
+		}
+		return parseDate(s)
+	}
+	return s, nil
+}
+
+// field maps a struct field to a column.
+type field struct {
+	index  int
+	column string
+	tagged bool
+}
+
+var fieldCache sync.Map // map[reflect.Type][]field
+
+// structFields returns the fields of t that can receive the values of a column.
+func structFields(t reflect.Type) []field {
+	if fields, ok := fieldCache.Load(t); ok {
+		return fields.([]field)
+	}
+
+	var fields []field
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		tag := f.Tag.Get("enigma")
+		switch tag {
+		case "-":
+			continue
+		case "":
+			fields = append(fields, field{index: i, column: f.Name})
+		default:
+			fields = append(fields, field{index: i, column: tag, tagged: true})
+		}
+	}
+	fieldCache.Store(t, fields)
+	return fields
+}
+
+// lookup returns the column mapped to f and its value. Untagged fields match columns case-insensitively.
+func lookup(record map[string]*string, f field) (string, *string, bool) {
+	if value, ok := record[f.column]; ok {
+		return f.column, value, true
+	}
+	if !f.tagged {
+		for column, value := range record {
+			if strings.EqualFold(column, f.column) {
+				return column, value, true
+			}
+		}
+	}
+	return "", nil, false
+}
@@ -0,0 +1,131 @@
+package enigma
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+var testColumns = []Column{
+	{ID: "namefull", Type: "type_varchar"},
+	{ID: "total_people", Type: "type_numeric"},
+	{ID: "ratio", Type: "type_numeric"},
+	{ID: "appt_made_date", Type: "type_date"},
+}
+
+const testRows = `[
+	{"namefull": "Smith, John", "total_people": "5", "ratio": "0.5", "appt_made_date": "2013-05-14T10:30:00"},
+	{"namefull": "Doe, Jane", "total_people": "12.0", "ratio": null, "appt_made_date": ""}
+]`
+
+type visit struct {
+	Name       string    `enigma:"namefull"`
+	People     int64     `enigma:"total_people"`
+	Ratio      *float64  `enigma:"ratio"`
+	Date       time.Time `enigma:"appt_made_date"`
+	Namefull   string
+	Ignored    string `enigma:"-"`
+	unexported string
+}
+
+func TestColumnKind(t *testing.T) {
+	kinds := map[string]ColumnKind{
+		"type_numeric":  NumericColumn,
+		"type_integer":  NumericColumn,
+		"type_date":     DateColumn,
+		"type_datetime": DateColumn,
+		"type_varchar":  StringColumn,
+		"type_text":     StringColumn,
+		"":              StringColumn,
+	}
+	for typ, kind := range kinds {
+		if k := (Column{Type: typ}).Kind(); k != kind {
+			t.Fatal(typ, k)
+		}
+	}
+}
+
+func TestDecodeRowsIntoStructs(t *testing.T) {
+	var visits []visit
+	if err := NewRowDecoder(testColumns).DecodeRows(json.RawMessage(testRows), &visits); err != nil {
+		t.Fatal(err)
+	}
+	if len(visits) != 2 {
+		t.Fatal(visits)
+	}
+
+	v := visits[0]
+	if v.Name != "Smith, John" || v.Namefull != v.Name || v.People != 5 || v.Ratio == nil || *v.Ratio != 0.5 {
+		t.Fatalf("%+v", v)
+	}
+	if !v.Date.Equal(time.Date(2013, 5, 14, 10, 30, 0, 0, time.UTC)) {
+		t.Fatal(v.Date)
+	}
+
+	v = visits[1]
+	if v.People != 12 || v.Ratio != nil || !v.Date.IsZero() {
+		t.Fatalf("%+v", v)
+	}
+}
+
+func TestDecodeRowsIntoMaps(t *testing.T) {
+	var rows []map[string]interface{}
+	if err := NewRowDecoder(testColumns).DecodeRows(json.RawMessage(testRows), &rows); err != nil {
+		t.Fatal(err)
+	}
+
+	row := rows[0]
+	if row["namefull"] != "Smith, John" || row["total_people"] != int64(5) || row["ratio"] != 0.5 {
+		t.Fatal(row)
+	}
+	if date, ok := row["appt_made_date"].(time.Time); !ok || date.Year() != 2013 {
+		t.Fatal(row["appt_made_date"])
+	}
+	if rows[1]["total_people"] != 12.0 || rows[1]["ratio"] != nil || rows[1]["appt_made_date"] != nil {
+		t.Fatal(rows[1])
+	}
+}
+
+func TestDecodeRow(t *testing.T) {
+	var v visit
+	if err := (*RowDecoder)(nil).DecodeRow(json.RawMessage(`{"namefull": "Smith", "total_people": 3}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v.Name != "Smith" || v.People != 3 {
+		t.Fatalf("%+v", v)
+	}
+}
+
+func TestDecodeRowsErrors(t *testing.T) {
+	var visits []visit
+	if err := NewRowDecoder(testColumns).DecodeRows(json.RawMessage(`[{"total_people": "many"}]`), &visits); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+	if err := NewRowDecoder(testColumns).DecodeRows(json.RawMessage(testRows), visits); err == nil {
+		t.Fatal("Expected error was not returned for a non-pointer")
+	}
+	var ints []int
+	if err := NewRowDecoder(testColumns).DecodeRows(json.RawMessage(testRows), &ints); err == nil {
+		t.Fatal("Expected error was not returned for an unsupported type")
+	}
+}
+
+func TestDataQueryDecoder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		columns, _ := json.Marshal(testColumns)
+		fmt.Fprintf(w, `{"result": {"columns": %s}}`, columns)
+	}))
+	defer server.Close()
+
+	decoder, err := NewClient(key, WithBaseURL(server.URL)).Data(datapath).Decoder(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoder.kind("total_people") != NumericColumn || decoder.kind("appt_made_date") != DateColumn {
+		t.Fatal(decoder.kinds)
+	}
+}
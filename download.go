@@ -0,0 +1,108 @@
+package enigma
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// Download requests the export, waits for the file to be ready, and returns a reader
+// streaming its decompressed CSV content. The reader must be closed by the caller.
+//
+// All requests, including the polling of the file, go through the HTTP stack of the client.
+//    r, err := client.Export("us.gov.whitehouse.visitor-list").Download(ctx)
+//    if err != nil {
+//        fmt.Println(err)
+//        return
+//    }
+//    defer r.Close()
+//    records, err := csv.NewReader(r).ReadAll()
+func (q *ExportQuery) Download(ctx context.Context) (io.ReadCloser, error) {
+	job, err := q.Start(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer job.Cancel()
+	return job.Download(ctx)
+}
+
+// DownloadTo is like Download but writes the decompressed CSV content to the file at path.
+// The file is written atomically: it is either complete, or left untouched.
+// Interrupted transfers are resumed as described on ExportJob.DownloadTo.
+func (q *ExportQuery) DownloadTo(ctx context.Context, path string) error {
+	job, err := q.Start(ctx)
+	if err != nil {
+		return err
+	}
+	defer job.Cancel()
+	return job.DownloadTo(ctx, path)
+}
+
+// open downloads the gzipped file at uri, decompressing it on the fly.
+func (client *Client) open(ctx context.Context, uri string) (io.ReadCloser, error) {
+	resp, err := client.sendFile(ctx, uri, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return nil, newAPIError(client.key, uri, resp, body)
+	}
+
+	// The transport already decompressed a file served with a gzip Content-Encoding.
+	if resp.Uncompressed {
+		return resp.Body, nil
+	}
+	gz, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return &gzipReadCloser{Reader: gz, body: resp.Body}, nil
+}
+
+// gzipReadCloser closes both a gzip.Reader and the body it decompresses.
+type gzipReadCloser struct {
+	*gzip.Reader
+	body io.Closer
+}
+
+func (r *gzipReadCloser) Close() error {
+	err := r.Reader.Close()
+	if berr := r.body.Close(); err == nil {
+		err = berr
+	}
+	return err
+}
+
+// writeFile writes the content of r to a temporary file, renamed to path once complete.
+func writeFile(path string, r io.Reader) (err error) {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = io.Copy(f, r); err != nil {
+		return err
+	}
+	if err = f.Chmod(0644); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
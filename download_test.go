@@ -0,0 +1,161 @@
+package enigma
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testModTime = time.Date(2015, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const testCSV = "namefull,total_people,appt_made_date\nSmith,5,2013-05-14\nDoe,12,2013-05-15\n"
+
+func gzipped(s string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	w.Write([]byte(s))
+	w.Close()
+	return buf.Bytes()
+}
+
+// newExportServer serves the export of a table whose content is csv, ready after notReady polls.
+func newExportServer(csv string, notReady int32) *httptest.Server {
+	var (
+		server *httptest.Server
+		polls  int32
+		file   = gzipped(csv)
+	)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/export/"):
+			fmt.Fprintf(w, `{"export_url": "%s/file.csv.gz", "head_url": "%s/file.csv.gz"}`, server.URL, server.URL)
+		case r.Method == http.MethodHead:
+			if atomic.AddInt32(&polls, 1) <= notReady {
+				w.WriteHeader(http.StatusNotFound)
+			}
+		default:
+			http.ServeContent(w, r, "file.csv.gz", testModTime, bytes.NewReader(file))
+		}
+	}))
+	return server
+}
+
+func TestExportQueryDownload(t *testing.T) {
+	server := newExportServer(testCSV, 0)
+	defer server.Close()
+
+	r, err := NewClient(key, WithBaseURL(server.URL)).Export(datapath).Download(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != testCSV {
+		t.Fatal(string(content))
+	}
+}
+
+func TestExportQueryDownloadTo(t *testing.T) {
+	server := newExportServer(testCSV, 0)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "enigma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "visitors.csv")
+	if err := NewClient(key, WithBaseURL(server.URL)).Export(datapath).DownloadTo(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil || string(content) != testCSV {
+		t.Fatal(err, string(content))
+	}
+	if files, _ := ioutil.ReadDir(dir); len(files) != 1 {
+		t.Fatal("Temporary files were left behind")
+	}
+}
+
+func TestExportQueryDownloadTimeout(t *testing.T) {
+	var (
+		server *httptest.Server
+		file   = gzipped(testCSV)
+	)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/export/"):
+			fmt.Fprintf(w, `{"export_url": "%s/file.csv.gz", "head_url": "%s/file.csv.gz"}`, server.URL, server.URL)
+		case r.Method == http.MethodGet:
+			// The file takes longer to stream than the time limit of the client.
+			w.Write(file[:10])
+			w.(http.Flusher).Flush()
+			time.Sleep(100 * time.Millisecond)
+			w.Write(file[10:])
+		}
+	}))
+	defer server.Close()
+
+	r, err := NewClient(key, WithBaseURL(server.URL), WithTimeout(50*time.Millisecond)).Export(datapath).Download(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	content, err := ioutil.ReadAll(r)
+	if err != nil || string(content) != testCSV {
+		t.Fatal(err, string(content))
+	}
+}
+
+func TestExportQueryDownloadMaxInFlight(t *testing.T) {
+	server := newExportServer(testCSV, 0)
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithMaxInFlight(1))
+	r, err := c.Export(datapath).Download(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	// The download still open must not hold the only slot of the client.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := c.Export(datapath).Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExportQueryDownloadError(t *testing.T) {
+	server := newErrorServer(http.StatusBadRequest, `{}`)
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "enigma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "visitors.csv")
+	if err := NewClient(key, WithBaseURL(server.URL)).Export(datapath).DownloadTo(context.Background(), path); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("No file should be created on failure")
+	}
+}
@@ -0,0 +1,95 @@
+package enigma
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// redacted replaces the API key in the URLs exposed by errors.
+const redacted = "REDACTED"
+
+// APIError is returned when the API responds with a status other than 200 OK.
+// Use errors.As to access its fields:
+//    var apiErr *enigma.APIError
+//    if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusBadRequest {
+//        fmt.Println(apiErr.Additional)
+//    }
+type APIError struct {
+	StatusCode int    // HTTP status code, e.g. 400.
+	Status     string // HTTP status, e.g. "400 Bad Request".
+	Type       string // Type of error reported by the API, if any.
+	Message    string // Message reported by the API, if any.
+	Additional string // Additional details reported by the API, if any.
+	URL        string // URL of the request, with the API key redacted.
+	Body       []byte // Raw body of the response.
+}
+
+func (e *APIError) Error() string {
+	msg := "enigma: " + e.Status
+	if e.Message != "" {
+		msg += ": " + e.Message
+	}
+	if e.Additional != "" {
+		msg += ": " + e.Additional
+	}
+	return msg
+}
+
+// newAPIError builds an APIError out of a failed response and its body.
+// It never fails: fields the body does not describe are simply left empty.
+func newAPIError(key, uri string, resp *http.Response, body []byte) *APIError {
+	e := &APIError{
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		URL:        redactKey(key, uri),
+		Body:       body,
+	}
+
+	var payload struct {
+		Info map[string]interface{} `json:"info"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		e.Type = stringField(payload.Info, "type")
+		e.Message = stringField(payload.Info, "message")
+		e.Additional = stringField(payload.Info, "additional")
+	}
+	return e
+}
+
+// stringField returns the value of the given field as a string,
+// falling back on its JSON representation when it is not one.
+func stringField(fields map[string]interface{}, name string) string {
+	switch v := fields[name].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return ""
+		}
+		return string(b)
+	}
+}
+
+// redactKey removes every occurrence of the API key from uri.
+func redactKey(key, uri string) string {
+	if key == "" {
+		return uri
+	}
+	return strings.Replace(uri, key, redacted, -1)
+}
+
+// redactError removes the API key from the URL of err, when it wraps a *url.Error,
+// as the errors returned by http.Client do.
+func redactError(key string, err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redactKey(key, urlErr.URL)
+	}
+	return err
+}
@@ -0,0 +1,67 @@
+package enigma
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func newErrorServer(status int, body string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+}
+
+func TestAPIError(t *testing.T) {
+	server := newErrorServer(http.StatusBadRequest, `{"info": {"type": "BadRequest", "message": "Invalid column", "additional": "blablabla is not a column"}}`)
+	defer server.Close()
+
+	_, err := NewClient(key, WithBaseURL(server.URL)).Data(datapath).Select("blablabla").Results()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Expected an *APIError, got", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Type != "BadRequest" || apiErr.Message != "Invalid column" || apiErr.Additional != "blablabla is not a column" {
+		t.Fatalf("%+v", apiErr)
+	}
+	if strings.Contains(apiErr.URL, key) || !strings.Contains(apiErr.URL, redacted) {
+		t.Fatal("API key was not redacted from the URL:", apiErr.URL)
+	}
+	if len(apiErr.Body) == 0 {
+		t.Fatal("Raw body is missing")
+	}
+}
+
+func TestAPIErrorMalformedBody(t *testing.T) {
+	for _, body := range []string{`{"info": "oops"}`, `{"info": {"additional": {"column": "x"}}}`, `[]`, `<html></html>`, ``} {
+		server := newErrorServer(http.StatusInternalServerError, body)
+		_, err := NewClient(key, WithBaseURL(server.URL)).Data(datapath).Results()
+		server.Close()
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Fatal("Expected an *APIError, got", err)
+		}
+		if apiErr.StatusCode != http.StatusInternalServerError {
+			t.Fatal(apiErr.StatusCode)
+		}
+	}
+}
+
+func TestTransportErrorRedacted(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	_, err := NewClient("SECRETKEY", WithBaseURL(server.URL)).Data(datapath).Results()
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		t.Fatal("Expected a *url.Error, got", err)
+	}
+	if strings.Contains(err.Error(), "SECRETKEY") || !strings.Contains(urlErr.URL, redacted) {
+		t.Fatal("The API key was not redacted:", err)
+	}
+}
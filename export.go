@@ -0,0 +1,243 @@
+package enigma
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ErrExportTimeout is returned when an exported file is still not ready after the polling timeout.
+var ErrExportTimeout = errors.New("enigma: timed out waiting for the export to be ready")
+
+// ExportStatus is the state of an ExportJob.
+type ExportStatus int
+
+// Export statuses
+const (
+	// ExportPending jobs are waiting for their file to be ready.
+	ExportPending ExportStatus = iota
+	// ExportReady jobs have a file ready to be downloaded.
+	ExportReady
+	// ExportFailed jobs stopped polling on an error, such as ErrExportTimeout.
+	ExportFailed
+	// ExportCanceled jobs were canceled before their file was ready.
+	ExportCanceled
+	// ExportDownloading exports are being downloaded by an ExportManager.
+	ExportDownloading
+	// ExportDone exports were downloaded by an ExportManager.
+	ExportDone
+)
+
+var exportStatuses = map[ExportStatus]string{
+	ExportPending:     "pending",
+	ExportReady:       "ready",
+	ExportFailed:      "failed",
+	ExportCanceled:    "canceled",
+	ExportDownloading: "downloading",
+	ExportDone:        "done",
+}
+
+func (s ExportStatus) String() string {
+	if name, ok := exportStatuses[s]; ok {
+		return name
+	}
+	return "pending"
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s ExportStatus) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (s *ExportStatus) UnmarshalText(text []byte) error {
+	for status, name := range exportStatuses {
+		if name == string(text) {
+			*s = status
+			return nil
+		}
+	}
+	return fmt.Errorf("enigma: unknown export status %q", text)
+}
+
+// ExportJob tracks an export until its file is ready to be downloaded.
+// Use ExportQuery.Start to create one.
+type ExportJob struct {
+	client    *Client
+	datapath  string
+	exportURL string
+	headURL   string
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+
+	mu     sync.Mutex
+	status ExportStatus
+	err    error
+}
+
+// Start requests the export and returns an ExportJob polling for the file to be ready
+// in the background, at the interval and up to the timeout set with WithExportPolling.
+// Polling stops when ctx is done or the job is canceled.
+//    job, err := client.Export("us.gov.whitehouse.visitor-list").Start(ctx)
+//    if err != nil {
+//        fmt.Println(err)
+//        return
+//    }
+//    url, err := job.Wait(ctx)
+func (q *ExportQuery) Start(ctx context.Context) (*ExportJob, error) {
+	response, err := q.export(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	job := q.client.newExportJob(q.datapath, response.ExportURL, response.HeadURL)
+	ctx, job.cancel = context.WithCancel(ctx)
+	go job.poll(ctx)
+	return job, nil
+}
+
+// newExportJob returns a pending job, which does not poll until told to.
+func (client *Client) newExportJob(datapath, exportURL, headURL string) *ExportJob {
+	return &ExportJob{
+		client:    client,
+		datapath:  datapath,
+		exportURL: exportURL,
+		headURL:   headURL,
+		done:      make(chan struct{}),
+	}
+}
+
+// poll checks whether the file is ready at regular intervals, until it is, the polling
+// timeout expires, or ctx is done.
+func (job *ExportJob) poll(ctx context.Context) {
+	defer job.cancel()
+	ticker := time.NewTicker(job.client.pollingInterval)
+	defer ticker.Stop()
+	timeout := time.NewTimer(job.client.pollingTimeout)
+	defer timeout.Stop()
+
+	for !job.check(ctx, job.client.retry) {
+		select {
+		case <-ticker.C:
+		case <-timeout.C:
+			job.finish(ExportFailed, ErrExportTimeout)
+			return
+		case <-ctx.Done():
+			job.finish(ExportCanceled, ctx.Err())
+			return
+		case <-job.done:
+			return
+		}
+	}
+}
+
+// check polls the head URL of the export, retrying according to policy, and reports whether
+// the job is over: its file is ready, or the head URL responded with an error.
+func (job *ExportJob) check(ctx context.Context, policy *RetryPolicy) bool {
+	ready, err := job.client.isReady(ctx, job.headURL, policy)
+	switch {
+	case err != nil:
+		job.finish(ExportFailed, err)
+	case ready:
+		job.finish(ExportReady, nil)
+	default:
+		return false
+	}
+	return true
+}
+
+// finish sets the final status of the job. Only the first call has an effect.
+func (job *ExportJob) finish(status ExportStatus, err error) {
+	job.once.Do(func() {
+		job.mu.Lock()
+		job.status, job.err = status, err
+		job.mu.Unlock()
+		close(job.done)
+	})
+}
+
+// result returns the status of the job, and the error that stopped it, if any.
+func (job *ExportJob) result() (ExportStatus, error) {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return job.status, job.err
+}
+
+// Datapath of the table exported.
+func (job *ExportJob) Datapath() string {
+	return job.datapath
+}
+
+// URL of the file to download once the job is ready.
+func (job *ExportJob) URL() string {
+	return job.exportURL
+}
+
+// Status returns the current status of the job.
+func (job *ExportJob) Status() ExportStatus {
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	return job.status
+}
+
+// Wait blocks until the file is ready and returns its URL, or returns the error that stopped
+// the job: ErrExportTimeout, an *APIError if the head URL responded with an unexpected status,
+// the error that prevented reaching it for good, context.Canceled, or the error of the context
+// passed to Start.
+// Canceling ctx only stops waiting, not the job.
+func (job *ExportJob) Wait(ctx context.Context) (string, error) {
+	select {
+	case <-job.done:
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+
+	job.mu.Lock()
+	defer job.mu.Unlock()
+	if job.err != nil {
+		return "", job.err
+	}
+	return job.exportURL, nil
+}
+
+// Cancel stops polling. Jobs that are already over are left untouched.
+func (job *ExportJob) Cancel() {
+	job.finish(ExportCanceled, context.Canceled)
+	if job.cancel != nil {
+		job.cancel()
+	}
+}
+
+// Download waits for the file to be ready, and returns a reader streaming its decompressed
+// CSV content. The reader must be closed by the caller.
+func (job *ExportJob) Download(ctx context.Context) (io.ReadCloser, error) {
+	url, err := job.Wait(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return job.client.open(ctx, url)
+}
+
+// DownloadTo waits for the file to be ready, and writes its decompressed CSV content to the file
+// at path. The file is written atomically: it is either complete, or left untouched.
+//
+// The compressed file is first downloaded to path+".part". Transfers interrupted mid-way are
+// resumed with Range requests when the server supports them, and start over otherwise.
+// The partial file is kept on failure, so that a later call for the same path picks up where
+// this one stopped, as long as the remote file did not change in between.
+func (job *ExportJob) DownloadTo(ctx context.Context, path string) error {
+	url, err := job.Wait(ctx)
+	if err != nil {
+		return err
+	}
+	part := path + ".part"
+	if err := job.client.fetch(ctx, url, part); err != nil {
+		return err
+	}
+	return unpack(part, path)
+}
@@ -0,0 +1,143 @@
+package enigma
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestExportJob(t *testing.T) {
+	server := newExportServer(testCSV, 2)
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, time.Second))
+	job, err := c.Export(datapath).Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Datapath() != datapath || job.URL() != server.URL+"/file.csv.gz" {
+		t.Fatal(job.Datapath(), job.URL())
+	}
+
+	url, err := job.Wait(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != job.URL() || job.Status() != ExportReady {
+		t.Fatal(url, job.Status())
+	}
+}
+
+func TestExportJobTimeout(t *testing.T) {
+	server := newExportServer(testCSV, 1000)
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, 20*time.Millisecond))
+	job, err := c.Export(datapath).Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := job.Wait(context.Background()); err != ErrExportTimeout {
+		t.Fatal("Expected ErrExportTimeout, got", err)
+	}
+	if job.Status() != ExportFailed {
+		t.Fatal(job.Status())
+	}
+}
+
+func TestExportJobError(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `{"export_url": "%s/file.csv.gz", "head_url": "%s/file.csv.gz"}`, server.URL, server.URL)
+	}))
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, time.Minute))
+	job, err := c.Export(datapath).Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var apiErr *APIError
+	if _, err := job.Wait(context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatal("Expected an *APIError, got", err)
+	}
+	if job.Status() != ExportFailed {
+		t.Fatal(job.Status())
+	}
+}
+
+func TestExportJobUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"export_url": "ftp://127.0.0.1/file.csv.gz", "head_url": "ftp://127.0.0.1/file.csv.gz"}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, time.Minute))
+	job, err := c.Export(datapath).Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := job.Wait(ctx); err == nil || err == ErrExportTimeout || ctx.Err() != nil {
+		t.Fatal("Expected the error reaching the head URL, got", err)
+	}
+	if job.Status() != ExportFailed {
+		t.Fatal(job.Status())
+	}
+}
+
+func TestExportJobCancel(t *testing.T) {
+	server := newExportServer(testCSV, 1000)
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, time.Minute))
+	job, err := c.Export(datapath).Start(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if job.Status() != ExportPending {
+		t.Fatal(job.Status())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := job.Wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Wait should return when its context is done, got", err)
+	}
+	if job.Status() != ExportPending {
+		t.Fatal("Canceling Wait should not stop the job", job.Status())
+	}
+
+	job.Cancel()
+	if _, err := job.Wait(context.Background()); !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+	if job.Status() != ExportCanceled {
+		t.Fatal(job.Status())
+	}
+}
+
+func TestExportJobStartContext(t *testing.T) {
+	server := newExportServer(testCSV, 1000)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, time.Minute))
+	job, err := c.Export(datapath).Start(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if _, err := job.Wait(context.Background()); !errors.Is(err, context.Canceled) || job.Status() != ExportCanceled {
+		t.Fatal(err, job.Status())
+	}
+}
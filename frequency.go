@@ -0,0 +1,127 @@
+package enigma
+
+import (
+	"context"
+	"sort"
+	"strconv"
+)
+
+// maxLimit is the maximum number of results the API returns per page.
+const maxLimit = 500
+
+// FrequencyIterator walks the frequency of each value of a column, page by page.
+//
+//    it := client.Stats("us.gov.whitehouse.visitor-list", "visitee_namelast").Frequencies(ctx)
+//    for it.Next() {
+//        fmt.Println(it.Entry().Value, it.Entry().Count)
+//    }
+//    if err := it.Err(); err != nil {
+//        fmt.Println(err)
+//    }
+type FrequencyIterator struct {
+	query   *StatsQuery
+	ctx     context.Context
+	page    int // Next page to fetch.
+	total   int // Total number of pages, -1 until the first page is fetched.
+	entries []FrequencyEntry
+	entry   FrequencyEntry
+	err     error
+}
+
+// Frequencies returns a FrequencyIterator over the frequency of each value of the column,
+// across all pages. Pages hold 500 entries, unless a different limit was set with Limit().
+func (q *StatsQuery) Frequencies(ctx context.Context) *FrequencyIterator {
+	query := q.Operation(Frequency)
+	if query.params.Get("limit") == "" {
+		query = query.Limit(maxLimit)
+	}
+	page, err := strconv.Atoi(q.params.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	return &FrequencyIterator{query: query, ctx: ctx, page: page, total: -1}
+}
+
+// Next advances the iterator to the next entry, fetching the next page if needed.
+// It returns false when all the entries have been read, or an error occurred.
+func (it *FrequencyIterator) Next() bool {
+	for len(it.entries) == 0 {
+		if it.err != nil || (it.total >= 0 && it.page > it.total) {
+			it.entry = FrequencyEntry{}
+			return false
+		}
+		it.advance()
+	}
+	it.entry, it.entries = it.entries[0], it.entries[1:]
+	return true
+}
+
+// Entry returns the current entry.
+func (it *FrequencyIterator) Entry() FrequencyEntry {
+	return it.entry
+}
+
+// Err returns the error, if any, that interrupted the iteration.
+func (it *FrequencyIterator) Err() error {
+	return it.err
+}
+
+// advance loads the entries of the next page.
+func (it *FrequencyIterator) advance() {
+	response, err := it.query.Page(it.page).ResultsContext(it.ctx)
+	if err != nil {
+		it.err = err
+		return
+	}
+	result, err := response.Decode()
+	if err != nil {
+		it.err = err
+		return
+	}
+	it.entries = result.Frequency
+	it.total = response.Info.TotalPages
+	it.page++
+}
+
+// DistributionEntry is a value of a column along with its share of the rows of the table.
+type DistributionEntry struct {
+	Value      string
+	Count      int64   // Number of rows holding the value.
+	Percent    float64 // Percentage of the rows holding the value.
+	Cumulative int64   // Number of rows holding this value or any of the values sorted before it.
+}
+
+// Distribution collects the frequency of every value of the column, across all pages,
+// sorted by decreasing count, then by value.
+func (q *StatsQuery) Distribution(ctx context.Context) ([]DistributionEntry, error) {
+	var (
+		entries []DistributionEntry
+		total   int64
+	)
+	it := q.Frequencies(ctx)
+	for it.Next() {
+		entry := it.Entry()
+		entries = append(entries, DistributionEntry{Value: entry.Value, Count: entry.Count})
+		total += entry.Count
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Value < entries[j].Value
+	})
+
+	var cumulative int64
+	for i := range entries {
+		cumulative += entries[i].Count
+		entries[i].Cumulative = cumulative
+		if total > 0 {
+			entries[i].Percent = float64(entries[i].Count) * 100 / float64(total)
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,68 @@
+package enigma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newFrequencyServer serves the frequency table of a column over two pages.
+func newFrequencyServer(t *testing.T) *httptest.Server {
+	pages := map[string]string{
+		"1": `[{"visitee_namelast": "potus", "count": "10"}, {"visitee_namelast": "flotus", "count": "5"}]`,
+		"2": `[{"visitee_namelast": "vpotus", "count": "5"}]`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("operation") != "frequency" || query.Get("limit") != "500" {
+			t.Error("Unexpected query", query)
+		}
+		fmt.Fprintf(w, `{"result": {"frequency": %s}, "info": {"column": "visitee_namelast", "operations": ["frequency"], "total_pages": 2}}`, pages[query.Get("page")])
+	}))
+}
+
+func TestFrequencyIterator(t *testing.T) {
+	server := newFrequencyServer(t)
+	defer server.Close()
+
+	it := NewClient(key, WithBaseURL(server.URL)).Stats(datapath, "visitee_namelast").Frequencies(context.Background())
+	var entries []FrequencyEntry
+	for it.Next() {
+		entries = append(entries, it.Entry())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 || entries[2] != (FrequencyEntry{"vpotus", 5}) {
+		t.Fatal(entries)
+	}
+}
+
+func TestDistribution(t *testing.T) {
+	server := newFrequencyServer(t)
+	defer server.Close()
+
+	entries, err := NewClient(key, WithBaseURL(server.URL)).Stats(datapath, "visitee_namelast").Distribution(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []DistributionEntry{
+		{Value: "potus", Count: 10, Percent: 50, Cumulative: 10},
+		{Value: "flotus", Count: 5, Percent: 25, Cumulative: 15},
+		{Value: "vpotus", Count: 5, Percent: 25, Cumulative: 20},
+	}
+	if fmt.Sprint(entries) != fmt.Sprint(expected) {
+		t.Fatal(entries)
+	}
+}
+
+func TestDistributionError(t *testing.T) {
+	server := newErrorServer(http.StatusBadRequest, `{}`)
+	defer server.Close()
+
+	if _, err := NewClient(key, WithBaseURL(server.URL)).Stats(datapath, "column").Distribution(context.Background()); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+}
@@ -0,0 +1,136 @@
+package enigma
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+)
+
+// dataPage is the outcome of fetching a single page of a data query.
+type dataPage struct {
+	response DataResponse
+	rows     []json.RawMessage
+	err      error
+}
+
+// DataIterator walks the rows of a data query page by page, fetching each page
+// only when the rows of the previous one have been consumed.
+//
+//    it := client.Data("us.gov.whitehouse.visitor-list").Iterator(ctx)
+//    defer it.Close()
+//    for it.Next() {
+//        fmt.Println(string(it.Row()))
+//    }
+//    if err := it.Err(); err != nil {
+//        fmt.Println(err)
+//    }
+type DataIterator struct {
+	query    *DataQuery
+	ctx      context.Context
+	cancel   context.CancelFunc
+	prefetch bool
+
+	page     int // Next page to fetch.
+	total    int // Total number of pages, -1 until the first page is fetched.
+	pending  chan dataPage
+	response DataResponse
+	rows     []json.RawMessage
+	row      json.RawMessage
+	err      error
+}
+
+// Iterator returns a DataIterator over the rows of every page of the query, starting
+// with the page set by Page(), or the first one.
+// The iterator stops fetching pages once ctx is done.
+func (q *DataQuery) Iterator(ctx context.Context) *DataIterator {
+	ctx, cancel := context.WithCancel(ctx)
+	page, err := strconv.Atoi(q.params.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	return &DataIterator{
+		query:  q,
+		ctx:    ctx,
+		cancel: cancel,
+		page:   page,
+		total:  -1,
+	}
+}
+
+// Prefetch makes the iterator fetch the next page in the background while the rows of the
+// current one are consumed. It must be called before the first call to Next.
+func (it *DataIterator) Prefetch() *DataIterator {
+	it.prefetch = true
+	return it
+}
+
+// Next advances the iterator to the next row, fetching the next page if needed.
+// It returns false when all the rows have been read, or an error occurred.
+func (it *DataIterator) Next() bool {
+	for len(it.rows) == 0 {
+		if it.err != nil || (it.total >= 0 && it.page > it.total) {
+			it.row = nil
+			return false
+		}
+		it.advance()
+	}
+	it.row, it.rows = it.rows[0], it.rows[1:]
+	return true
+}
+
+// Row returns the JSON representation of the current row.
+func (it *DataIterator) Row() json.RawMessage {
+	return it.row
+}
+
+// Response returns the response of the last page fetched.
+func (it *DataIterator) Response() DataResponse {
+	return it.response
+}
+
+// Err returns the error, if any, that interrupted the iteration.
+func (it *DataIterator) Err() error {
+	return it.err
+}
+
+// Close stops the iterator and any page being fetched in the background.
+func (it *DataIterator) Close() {
+	it.cancel()
+}
+
+// advance loads the rows of the next page.
+func (it *DataIterator) advance() {
+	var p dataPage
+	if it.pending != nil {
+		p = <-it.pending
+		it.pending = nil
+	} else {
+		p = it.fetch(it.page)
+	}
+	if p.err != nil {
+		it.err = p.err
+		return
+	}
+
+	it.response, it.rows = p.response, p.rows
+	it.total = p.response.Info.TotalPages
+	it.page++
+
+	if it.prefetch && it.page <= it.total {
+		it.pending = make(chan dataPage, 1)
+		go func(pending chan<- dataPage, page int) {
+			pending <- it.fetch(page)
+		}(it.pending, it.page)
+	}
+}
+
+// fetch retrieves the given page.
+func (it *DataIterator) fetch(page int) (p dataPage) {
+	if p.response, p.err = it.query.Page(page).ResultsContext(it.ctx); p.err != nil {
+		return
+	}
+	if len(p.response.Result) > 0 {
+		p.err = json.Unmarshal(p.response.Result, &p.rows)
+	}
+	return
+}
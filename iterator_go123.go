@@ -0,0 +1,62 @@
+//go:build go1.23
+
+package enigma
+
+import (
+	"encoding/json"
+	"iter"
+)
+
+// All returns an iterator to be used with a range loop. It yields each row along with
+// a nil error, then the error that interrupted the iteration, if any.
+// The DataIterator is closed when the loop ends.
+//
+//    for row, err := range client.Data("us.gov.whitehouse.visitor-list").Iterator(ctx).All() {
+//        if err != nil {
+//            return err
+//        }
+//        fmt.Println(string(row))
+//    }
+func (it *DataIterator) All() iter.Seq2[json.RawMessage, error] {
+	return func(yield func(json.RawMessage, error) bool) {
+		defer it.Close()
+		for it.Next() {
+			if !yield(it.Row(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(nil, err)
+		}
+	}
+}
+
+// All returns an iterator to be used with a range loop. It yields each entry along with
+// a nil error, then the error that interrupted the iteration, if any.
+func (it *FrequencyIterator) All() iter.Seq2[FrequencyEntry, error] {
+	return func(yield func(FrequencyEntry, error) bool) {
+		for it.Next() {
+			if !yield(it.Entry(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(FrequencyEntry{}, err)
+		}
+	}
+}
+
+// All returns an iterator to be used with a range loop. It yields each table along with
+// a nil error, then the error that interrupted the iteration, if any.
+func (it *ChildTableIterator) All() iter.Seq2[ChildTable, error] {
+	return func(yield func(ChildTable, error) bool) {
+		for it.Next() {
+			if !yield(it.Table(), nil) {
+				return
+			}
+		}
+		if err := it.Err(); err != nil {
+			yield(ChildTable{}, err)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+//go:build go1.23
+
+package enigma
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDataIteratorAll(t *testing.T) {
+	server, _ := newPagedServer(2)
+	defer server.Close()
+
+	var count int
+	for row, err := range NewClient(key, WithBaseURL(server.URL)).Data(datapath).Iterator(context.Background()).Prefetch().All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(row) == 0 {
+			t.Fatal("Empty row")
+		}
+		count++
+	}
+	if count != 4 {
+		t.Fatal(count)
+	}
+}
+
+func TestChildTableIteratorAll(t *testing.T) {
+	server := newCatalogServer()
+	defer server.Close()
+
+	var count int
+	for table, err := range NewClient(key, WithBaseURL(server.URL)).Meta().ChildrenTables(context.Background(), "us.gov").All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if table.Datapath == "" {
+			t.Fatal("Empty table")
+		}
+		count++
+	}
+	if count != 3 {
+		t.Fatal(count)
+	}
+}
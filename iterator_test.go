@@ -0,0 +1,92 @@
+package enigma
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// newPagedServer serves totalPages pages of two rows each and records the pages requested.
+func newPagedServer(totalPages int) (*httptest.Server, func() []string) {
+	var (
+		mu    sync.Mutex
+		pages []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		pages = append(pages, r.URL.Query()["page"]...)
+		mu.Unlock()
+
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		fmt.Fprintf(w, `{"result": [{"n": "%d-1"}, {"n": "%d-2"}], "info": {"current_page": %d, "total_pages": %d}}`, page, page, page, totalPages)
+	}))
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), pages...)
+	}
+}
+
+func TestDataIterator(t *testing.T) {
+	for _, prefetch := range []bool{false, true} {
+		server, pages := newPagedServer(3)
+		query := NewClient(key, WithBaseURL(server.URL)).Data(datapath)
+
+		it := query.Iterator(context.Background())
+		if prefetch {
+			it.Prefetch()
+		}
+		var rows []string
+		for it.Next() {
+			rows = append(rows, string(it.Row()))
+		}
+		it.Close()
+		server.Close()
+
+		if err := it.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) != 6 || rows[0] != `{"n": "1-1"}` || rows[5] != `{"n": "3-2"}` {
+			t.Fatal(rows)
+		}
+		if p := pages(); fmt.Sprint(p) != "[1 2 3]" {
+			t.Fatal("Each page should be requested exactly once:", p)
+		}
+		if query.params.Get("page") != "" {
+			t.Fatal("Iterator should not alter the query")
+		}
+	}
+}
+
+func TestDataIteratorStartPage(t *testing.T) {
+	server, pages := newPagedServer(3)
+	defer server.Close()
+
+	it := NewClient(key, WithBaseURL(server.URL)).Data(datapath).Page(2).Iterator(context.Background())
+	defer it.Close()
+	var count int
+	for it.Next() {
+		count++
+	}
+	if count != 4 || fmt.Sprint(pages()) != "[2 3]" {
+		t.Fatal(count, pages())
+	}
+}
+
+func TestDataIteratorError(t *testing.T) {
+	server := newErrorServer(http.StatusBadRequest, `{}`)
+	defer server.Close()
+
+	it := NewClient(key, WithBaseURL(server.URL)).Data(datapath).Iterator(context.Background())
+	defer it.Close()
+	if it.Next() {
+		t.Fatal("No row should be returned")
+	}
+	if it.Err() == nil {
+		t.Fatal("Expected error was not returned")
+	}
+}
@@ -0,0 +1,171 @@
+package enigma
+
+import (
+	"context"
+	"io"
+	"math"
+	"sync"
+	"time"
+)
+
+// WithRateLimit caps the rate at which the client sends requests, retries included,
+// to requestsPerSecond, allowing bursts of up to burst requests.
+// Requests exceeding the rate wait for their turn, or until their context is done.
+func WithRateLimit(requestsPerSecond float64, burst int) Option {
+	return func(client *Client) {
+		if requestsPerSecond <= 0 {
+			client.limiter.bucket = nil
+			return
+		}
+		if burst < 1 {
+			burst = 1
+		}
+		client.limiter.bucket = &tokenBucket{
+			rate:   requestsPerSecond,
+			burst:  float64(burst),
+			tokens: float64(burst),
+		}
+	}
+}
+
+// WithMaxInFlight caps the number of requests the client can have in flight at once.
+// A request remains in flight until the body of its response has been read and closed.
+//
+// Downloads of exported files, which can take long to read, are not counted, so that they
+// do not hold up polling and metadata requests. ExportManagerConfig.MaxDownloads bounds them
+// for an ExportManager.
+func WithMaxInFlight(n int) Option {
+	return func(client *Client) {
+		if n <= 0 {
+			client.limiter.slots = nil
+			return
+		}
+		client.limiter.slots = make(chan struct{}, n)
+	}
+}
+
+// QueueStats reports how long requests waited for the rate limiter and the concurrency cap of a client.
+type QueueStats struct {
+	Requests  int64         // Number of requests that went through the limiter.
+	Queued    int64         // Number of requests that had to wait before being sent.
+	TotalWait time.Duration // Cumulated time spent waiting.
+	MaxWait   time.Duration // Longest time a single request waited.
+}
+
+// QueueStats returns statistics on the time requests spent queued by the limits
+// configured with WithRateLimit and WithMaxInFlight.
+func (client *Client) QueueStats() QueueStats {
+	client.limiter.mu.Lock()
+	defer client.limiter.mu.Unlock()
+	return client.limiter.stats
+}
+
+// tokenBucket is a token bucket refilled continuously at rate tokens per second.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// reserve takes a token from the bucket and returns how long to wait before it can be used.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := time.Now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel returns a token reserved by a request that gave up waiting.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	b.tokens = math.Min(b.burst, b.tokens+1)
+	b.mu.Unlock()
+}
+
+// limiter queues requests according to the rate limit and concurrency cap of a client.
+// Both are optional.
+type limiter struct {
+	bucket *tokenBucket
+	slots  chan struct{}
+
+	mu    sync.Mutex
+	stats QueueStats
+}
+
+// wait blocks until a request can be sent. When capped is true, the request also waits for
+// a slot of the concurrency cap, and the returned function must be called once the request
+// is over to free it.
+func (l *limiter) wait(ctx context.Context, capped bool) (release func(), err error) {
+	start := time.Now()
+	release = func() {}
+
+	if l.bucket != nil {
+		if delay := l.bucket.reserve(); delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-timer.C:
+			case <-ctx.Done():
+				timer.Stop()
+				l.bucket.cancel()
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	if capped && l.slots != nil {
+		select {
+		case l.slots <- struct{}{}:
+		case <-ctx.Done():
+			if l.bucket != nil {
+				l.bucket.cancel()
+			}
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() {
+			once.Do(func() { <-l.slots })
+		}
+	}
+
+	l.record(time.Since(start))
+	return release, nil
+}
+
+func (l *limiter) record(wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.stats.Requests++
+	// Below a millisecond, the wait is the cost of going through the limiter rather than queuing.
+	if wait >= time.Millisecond {
+		l.stats.Queued++
+		l.stats.TotalWait += wait
+	}
+	if wait > l.stats.MaxWait {
+		l.stats.MaxWait = wait
+	}
+}
+
+// releaseOnClose frees the slot of a request once its response body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
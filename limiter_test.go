@@ -0,0 +1,103 @@
+package enigma
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	bucket := &tokenBucket{rate: 10, burst: 2, tokens: 2}
+	if bucket.reserve() != 0 || bucket.reserve() != 0 {
+		t.Fatal("Burst should not wait")
+	}
+	if wait := bucket.reserve(); wait < 90*time.Millisecond || wait > 100*time.Millisecond {
+		t.Fatal(wait)
+	}
+	bucket.cancel()
+	if wait := bucket.reserve(); wait < 90*time.Millisecond || wait > 100*time.Millisecond {
+		t.Fatal("Canceled reservation was not returned", wait)
+	}
+}
+
+func TestLimiterCancelReturnsToken(t *testing.T) {
+	l := &limiter{
+		bucket: &tokenBucket{rate: 0.001, burst: 1, tokens: 1},
+		slots:  make(chan struct{}, 1),
+	}
+	l.slots <- struct{}{}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := l.wait(ctx, true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected the wait for a slot to be interrupted by the context, got", err)
+	}
+	if wait := l.bucket.reserve(); wait != 0 {
+		t.Fatal("The token taken before waiting for a slot was not returned", wait)
+	}
+}
+
+func TestWithMaxInFlight(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithMaxInFlight(2))
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := c.Data(datapath).Results(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if maxInFlight > 2 {
+		t.Fatal("Too many requests in flight:", maxInFlight)
+	}
+	stats := c.QueueStats()
+	if stats.Requests != 10 || stats.Queued == 0 || stats.MaxWait == 0 || stats.TotalWait < stats.MaxWait {
+		t.Fatalf("%+v", stats)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithRateLimit(0.1, 1))
+	if _, err := c.Data(datapath).Results(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := c.Data(datapath).ResultsContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatal("Expected the wait to be interrupted by the context, got", err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("Waiting did not honour the context")
+	}
+}
@@ -0,0 +1,398 @@
+package enigma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultMaxDownloads = 2
+
+// ExportManagerConfig configures an ExportManager.
+type ExportManagerConfig struct {
+	// StateFile, if set, is the path of a JSON file where the state of the exports is saved
+	// after every change. A manager created with the same file resumes where the previous one
+	// stopped, without requesting the exports again.
+	StateFile string
+	// MaxDownloads is the maximum number of files downloaded at the same time. Defaults to 2.
+	// Downloads are not counted by the concurrency cap set with WithMaxInFlight.
+	MaxDownloads int
+	// OnProgress, if set, is called every time the status of an export changes.
+	// Calls are never made concurrently.
+	OnProgress func(ExportProgress)
+}
+
+// ExportProgress describes the state of an export handled by an ExportManager.
+type ExportProgress struct {
+	Datapath string
+	Path     string
+	Status   ExportStatus
+	Err      error
+}
+
+// ExportManagerError lists the exports that failed during a run of an ExportManager.
+type ExportManagerError struct {
+	Failed []ExportProgress
+}
+
+func (e *ExportManagerError) Error() string {
+	failures := make([]string, len(e.Failed))
+	for i, p := range e.Failed {
+		failures[i] = fmt.Sprintf("%s: %v", p.Datapath, p.Err)
+	}
+	return fmt.Sprintf("enigma: %d exports failed: %s", len(e.Failed), strings.Join(failures, "; "))
+}
+
+// ExportManager exports many tables and downloads their files.
+// A single scheduler polls every pending export at the interval set with WithExportPolling,
+// and downloads the files that are ready, a few at a time.
+//    manager, err := client.NewExportManager(enigma.ExportManagerConfig{StateFile: "exports.json"})
+//    if err != nil {
+//        fmt.Println(err)
+//        return
+//    }
+//    for _, datapath := range datapaths {
+//        if err := manager.Submit(ctx, client.Export(datapath), datapath+".csv"); err != nil {
+//            fmt.Println(err)
+//            return
+//        }
+//    }
+//    err = manager.Run(ctx)
+type ExportManager struct {
+	client *Client
+	config ExportManagerConfig
+	wake   chan struct{}
+
+	mu         sync.Mutex
+	exports    []*managedExport
+	submitting map[string]bool // Paths of the exports being requested by Submit.
+
+	progress sync.Mutex
+}
+
+// managedExport is an export handled by an ExportManager.
+type managedExport struct {
+	job       *ExportJob
+	path      string
+	submitted time.Time
+	waiting   time.Time // Start of the polling timeout, reset when loaded from a state file.
+	status    ExportStatus
+	err       error
+}
+
+// exportRecord is the saved state of a managedExport.
+type exportRecord struct {
+	Datapath  string       `json:"datapath"`
+	Path      string       `json:"path"`
+	ExportURL string       `json:"export_url"`
+	HeadURL   string       `json:"head_url"`
+	Submitted time.Time    `json:"submitted"`
+	Status    ExportStatus `json:"status"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// NewExportManager returns an ExportManager, loading the state saved in config.StateFile if it exists.
+func (client *Client) NewExportManager(config ExportManagerConfig) (*ExportManager, error) {
+	if config.MaxDownloads <= 0 {
+		config.MaxDownloads = defaultMaxDownloads
+	}
+	m := &ExportManager{
+		client:     client,
+		config:     config,
+		wake:       make(chan struct{}, 1),
+		submitting: make(map[string]bool),
+	}
+	if config.StateFile == "" {
+		return m, nil
+	}
+
+	data, err := ioutil.ReadFile(config.StateFile)
+	if os.IsNotExist(err) {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var records []exportRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("enigma: invalid export state file %s: %v", config.StateFile, err)
+	}
+	for _, r := range records {
+		e := &managedExport{
+			job:       client.newExportJob(r.Datapath, r.ExportURL, r.HeadURL),
+			path:      r.Path,
+			submitted: r.Submitted,
+			waiting:   time.Now(),
+			status:    r.Status,
+		}
+		if r.Error != "" {
+			e.err = errors.New(r.Error)
+		}
+		switch e.status {
+		case ExportDownloading:
+			// The previous process stopped during the download, resumed by the next run.
+			e.status = ExportReady
+			fallthrough
+		case ExportReady:
+			e.job.finish(ExportReady, nil)
+		}
+		m.exports = append(m.exports, e)
+	}
+	return m, nil
+}
+
+// Submit requests the export of q, to be downloaded to the file at path by Run.
+// Nothing is requested if an export to path is already in progress, such as one loaded from
+// the state file, or being requested by another call. Exports to path that are over,
+// successfully or not, are replaced.
+func (m *ExportManager) Submit(ctx context.Context, q *ExportQuery, path string) error {
+	m.mu.Lock()
+	if m.submitting[path] {
+		m.mu.Unlock()
+		return nil
+	}
+	for _, e := range m.exports {
+		if e.path == path && !e.over() {
+			m.mu.Unlock()
+			return nil
+		}
+	}
+	// The path is reserved while the export is requested, so concurrent calls do not request it too.
+	m.submitting[path] = true
+	m.mu.Unlock()
+
+	response, err := q.export(ctx)
+	if err != nil {
+		m.mu.Lock()
+		delete(m.submitting, path)
+		m.mu.Unlock()
+		return err
+	}
+	now := time.Now()
+	e := &managedExport{
+		job:       q.client.newExportJob(q.datapath, response.ExportURL, response.HeadURL),
+		path:      path,
+		submitted: now,
+		waiting:   now,
+		status:    ExportPending,
+	}
+
+	m.mu.Lock()
+	delete(m.submitting, path)
+	replaced := false
+	for i, existing := range m.exports {
+		if existing.path == path {
+			m.exports[i], replaced = e, true
+			break
+		}
+	}
+	if !replaced {
+		m.exports = append(m.exports, e)
+	}
+	err = m.save()
+	m.mu.Unlock()
+
+	m.notify(e.progress())
+	return err
+}
+
+// Exports returns the state of every export handled by the manager, in the order they were submitted.
+func (m *ExportManager) Exports() []ExportProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	exports := make([]ExportProgress, len(m.exports))
+	for i, e := range m.exports {
+		exports[i] = e.progress()
+	}
+	return exports
+}
+
+// Run polls the pending exports and downloads their files once ready, until every export is over
+// or ctx is done. Exports submitted while running are picked up. Exports are given up on when not
+// ready within the timeout set with WithExportPolling, counted from their submission, or from
+// the creation of the manager for exports loaded from the state file.
+//
+// Run returns an *ExportManagerError listing the exports that failed during the run, if any.
+// Exports interrupted by the cancellation of ctx are resumed by the next run.
+func (m *ExportManager) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.client.pollingInterval)
+	defer ticker.Stop()
+
+	// Exports that failed before this run, such as those loaded from the state file, are not reported.
+	previous := make(map[*managedExport]bool)
+	for _, e := range m.withStatus(ExportFailed) {
+		previous[e] = true
+	}
+
+	slots := make(chan struct{}, m.config.MaxDownloads)
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		if m.poll(ctx) {
+			break
+		}
+		// Only Run takes slots, which makes checking for a free one before taking it safe.
+		for _, e := range m.withStatus(ExportReady) {
+			if len(slots) == cap(slots) {
+				break
+			}
+			slots <- struct{}{}
+			m.update(e, ExportDownloading, nil)
+			wg.Add(1)
+			go func(e *managedExport) {
+				defer wg.Done()
+				m.download(ctx, e)
+				<-slots
+				m.signal()
+			}(e)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-m.wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	var failed []ExportProgress
+	for _, e := range m.withStatus(ExportFailed) {
+		if !previous[e] {
+			failed = append(failed, m.progressOf(e))
+		}
+	}
+	if len(failed) > 0 {
+		return &ExportManagerError{Failed: failed}
+	}
+	return nil
+}
+
+// poll checks whether the pending exports are ready, and reports whether every export is over.
+func (m *ExportManager) poll(ctx context.Context) bool {
+	for _, e := range m.withStatus(ExportPending) {
+		// Exports failing with a transient error are polled again at the next tick: retrying
+		// them right away would hold up the other exports.
+		if e.job.check(ctx, nil) {
+			status, err := e.job.result()
+			m.update(e, status, err)
+		} else if time.Since(e.waiting) > m.client.pollingTimeout {
+			e.job.finish(ExportFailed, ErrExportTimeout)
+			m.update(e, ExportFailed, ErrExportTimeout)
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, e := range m.exports {
+		if !e.over() {
+			return false
+		}
+	}
+	return true
+}
+
+// download writes the file of e to its path.
+func (m *ExportManager) download(ctx context.Context, e *managedExport) {
+	err := e.job.DownloadTo(ctx, e.path)
+	switch {
+	case err == nil:
+		m.update(e, ExportDone, nil)
+	case ctx.Err() != nil:
+		m.update(e, ExportReady, nil)
+	default:
+		m.update(e, ExportFailed, err)
+	}
+}
+
+// progressOf returns the progress of e.
+func (m *ExportManager) progressOf(e *managedExport) ExportProgress {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return e.progress()
+}
+
+// withStatus returns the exports with the given status.
+func (m *ExportManager) withStatus(status ExportStatus) []*managedExport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var exports []*managedExport
+	for _, e := range m.exports {
+		if e.status == status {
+			exports = append(exports, e)
+		}
+	}
+	return exports
+}
+
+// update changes the status of e, saves the state of the manager, and reports the change.
+// Errors saving the state are reported with the progress of e.
+func (m *ExportManager) update(e *managedExport, status ExportStatus, err error) {
+	m.mu.Lock()
+	e.status, e.err = status, err
+	p := e.progress()
+	if serr := m.save(); serr != nil && p.Err == nil {
+		p.Err = serr
+	}
+	m.mu.Unlock()
+	m.notify(p)
+}
+
+// save writes the state of the manager to its state file. It must be called with m.mu held.
+func (m *ExportManager) save() error {
+	if m.config.StateFile == "" {
+		return nil
+	}
+	records := make([]exportRecord, len(m.exports))
+	for i, e := range m.exports {
+		records[i] = exportRecord{
+			Datapath:  e.job.datapath,
+			Path:      e.path,
+			ExportURL: e.job.exportURL,
+			HeadURL:   e.job.headURL,
+			Submitted: e.submitted,
+			Status:    e.status,
+		}
+		if e.err != nil {
+			records[i].Error = e.err.Error()
+		}
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFile(m.config.StateFile, bytes.NewReader(data))
+}
+
+// notify passes p to the progress callback, if any.
+func (m *ExportManager) notify(p ExportProgress) {
+	if m.config.OnProgress == nil {
+		return
+	}
+	m.progress.Lock()
+	defer m.progress.Unlock()
+	m.config.OnProgress(p)
+}
+
+// signal wakes Run up without waiting for the next polling tick.
+func (m *ExportManager) signal() {
+	select {
+	case m.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (e *managedExport) over() bool {
+	return e.status == ExportDone || e.status == ExportFailed || e.status == ExportCanceled
+}
+
+func (e *managedExport) progress() ExportProgress {
+	return ExportProgress{Datapath: e.job.datapath, Path: e.path, Status: e.status, Err: e.err}
+}
@@ -0,0 +1,291 @@
+package enigma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestExportManager(t *testing.T) {
+	server := newExportServer(testCSV, 2)
+	defer server.Close()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	var (
+		downloading, maxDownloading int
+		statuses                    = make(map[string][]ExportStatus)
+	)
+	config := ExportManagerConfig{
+		StateFile:    filepath.Join(dir, "state.json"),
+		MaxDownloads: 1,
+		OnProgress: func(p ExportProgress) {
+			statuses[p.Path] = append(statuses[p.Path], p.Status)
+			switch p.Status {
+			case ExportDownloading:
+				if downloading++; downloading > maxDownloading {
+					maxDownloading = downloading
+				}
+			case ExportDone, ExportFailed:
+				downloading--
+			}
+		},
+	}
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, time.Second))
+	m, err := c.NewExportManager(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{filepath.Join(dir, "a.csv"), filepath.Join(dir, "b.csv"), filepath.Join(dir, "c.csv")}
+	for _, path := range paths {
+		if err := m.Submit(context.Background(), c.Export(datapath), path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		content, err := ioutil.ReadFile(path)
+		if err != nil || string(content) != testCSV {
+			t.Fatal(err, string(content))
+		}
+		if got := statuses[path]; len(got) != 4 || got[0] != ExportPending || got[3] != ExportDone {
+			t.Fatal(path, got)
+		}
+	}
+	if maxDownloading != 1 {
+		t.Fatal("Expected one download at a time, got", maxDownloading)
+	}
+
+	var records []exportRecord
+	data, _ := ioutil.ReadFile(config.StateFile)
+	if err := json.Unmarshal(data, &records); err != nil || len(records) != 3 || records[0].Status != ExportDone {
+		t.Fatal(err, string(data))
+	}
+}
+
+func TestExportManagerResume(t *testing.T) {
+	server := newExportServer(testCSV, 0)
+	defer server.Close()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "visitors.csv")
+	state := filepath.Join(dir, "state.json")
+	records := []exportRecord{{
+		Datapath:  datapath,
+		Path:      path,
+		ExportURL: server.URL + "/file.csv.gz",
+		HeadURL:   server.URL + "/file.csv.gz",
+		Submitted: time.Now(),
+		Status:    ExportDownloading,
+	}}
+	data, _ := json.Marshal(records)
+	if err := ioutil.WriteFile(state, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Exports in progress are not requested again.
+	api := newErrorServer(http.StatusInternalServerError, `{}`)
+	defer api.Close()
+	c := NewClient(key, WithBaseURL(api.URL), WithExportPolling(time.Millisecond, time.Second))
+	m, err := c.NewExportManager(ExportManagerConfig{StateFile: state})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Submit(context.Background(), c.Export(datapath), path); err != nil {
+		t.Fatal(err)
+	}
+	if exports := m.Exports(); len(exports) != 1 || exports[0].Status != ExportReady {
+		t.Fatal(exports)
+	}
+
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil || string(content) != testCSV {
+		t.Fatal(err, string(content))
+	}
+}
+
+func TestExportManagerResumeAfterTimeout(t *testing.T) {
+	server := newExportServer(testCSV, 1)
+	defer server.Close()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// The process stopped long ago, after an export failed and while another one was pending.
+	path := filepath.Join(dir, "visitors.csv")
+	state := filepath.Join(dir, "state.json")
+	records := []exportRecord{
+		{
+			Datapath:  datapath,
+			Path:      path,
+			ExportURL: server.URL + "/file.csv.gz",
+			HeadURL:   server.URL + "/file.csv.gz",
+			Submitted: time.Now().Add(-time.Hour),
+			Status:    ExportPending,
+		},
+		{
+			Datapath:  datapath,
+			Path:      filepath.Join(dir, "failed.csv"),
+			Submitted: time.Now().Add(-time.Hour),
+			Status:    ExportFailed,
+			Error:     ErrExportTimeout.Error(),
+		},
+	}
+	data, _ := json.Marshal(records)
+	if err := ioutil.WriteFile(state, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := NewClient(key, WithExportPolling(time.Millisecond, time.Second))
+	m, err := c.NewExportManager(ExportManagerConfig{StateFile: state})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Run(context.Background()); err != nil {
+		t.Fatal("Failures of previous runs should not be reported, got", err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil || string(content) != testCSV {
+		t.Fatal(err, string(content))
+	}
+}
+
+// newMultiExportServer serves exports whose head URL always fails with 503 Service Unavailable
+// for datapaths containing "broken", and are ready otherwise. Export requests take 50ms.
+func newMultiExportServer() (*httptest.Server, *int32) {
+	var (
+		server  *httptest.Server
+		exports int32
+		file    = gzipped(testCSV)
+	)
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case strings.Contains(r.URL.Path, "/export/"):
+			atomic.AddInt32(&exports, 1)
+			time.Sleep(50 * time.Millisecond)
+			name := "file"
+			if strings.Contains(r.URL.Path, "broken") {
+				name = "broken"
+			}
+			fmt.Fprintf(w, `{"export_url": "%s/%s.csv.gz", "head_url": "%s/%s.csv.gz"}`, server.URL, name, server.URL, name)
+		case strings.Contains(r.URL.Path, "broken"):
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			http.ServeContent(w, r, "file.csv.gz", testModTime, bytes.NewReader(file))
+		}
+	}))
+	return server, &exports
+}
+
+func TestExportManagerFailingHead(t *testing.T) {
+	server, _ := newMultiExportServer()
+	defer server.Close()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// Retrying the failing head URL would hold up the scheduler for seconds.
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, 300*time.Millisecond),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 5, MinBackoff: time.Second}))
+	m, err := c.NewExportManager(ExportManagerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := m.Submit(ctx, c.Export("us.broken"), filepath.Join(dir, "broken.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Submit(ctx, c.Export(datapath), filepath.Join(dir, "visitors.csv")); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	err = m.Run(ctx)
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatal("Polling was held up by the failing head URL for", elapsed)
+	}
+	var merr *ExportManagerError
+	if !errors.As(err, &merr) || len(merr.Failed) != 1 || merr.Failed[0].Err != ErrExportTimeout {
+		t.Fatal("Expected the broken export to time out, got", err)
+	}
+	if exports := m.Exports(); exports[1].Status != ExportDone {
+		t.Fatal(exports[1])
+	}
+}
+
+func TestExportManagerConcurrentSubmit(t *testing.T) {
+	server, exports := newMultiExportServer()
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL))
+	m, err := c.NewExportManager(ExportManagerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := m.Submit(context.Background(), c.Export(datapath), "visitors.csv"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := atomic.LoadInt32(exports); n != 1 || len(m.Exports()) != 1 {
+		t.Fatal("Expected a single export, got", n, m.Exports())
+	}
+}
+
+func TestExportManagerTimeout(t *testing.T) {
+	server := newExportServer(testCSV, 1000)
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithExportPolling(time.Millisecond, 20*time.Millisecond))
+	m, err := c.NewExportManager(ExportManagerConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Submit(context.Background(), c.Export(datapath), filepath.Join(os.TempDir(), "never.csv")); err != nil {
+		t.Fatal(err)
+	}
+
+	err = m.Run(context.Background())
+	var merr *ExportManagerError
+	if !errors.As(err, &merr) || len(merr.Failed) != 1 || merr.Failed[0].Err != ErrExportTimeout {
+		t.Fatal("Expected ErrExportTimeout, got", err)
+	}
+}
+
+func TestExportManagerInvalidState(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	state := filepath.Join(dir, "state.json")
+	if err := ioutil.WriteFile(state, []byte(`[{"status": "lost"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClient(key).NewExportManager(ExportManagerConfig{StateFile: state}); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+}
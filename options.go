@@ -0,0 +1,68 @@
+package enigma
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Option customizes a Client. Options are passed to NewClient.
+type Option func(*Client)
+
+// WithHTTPClient sends every request, including the polling of exported files,
+// through the given http.Client instead of http.DefaultClient.
+//
+// Use it to configure proxies, TLS settings or a custom http.RoundTripper.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(client *Client) {
+		if httpClient != nil {
+			client.httpClient = httpClient
+		}
+	}
+}
+
+// WithTimeout sets a time limit for each request made by the client.
+// The http.Client passed to WithHTTPClient, if any, is left untouched.
+//
+// Downloads of exported files, which may take much longer, are only limited by their context:
+// neither this limit nor the Timeout of an http.Client passed to WithHTTPClient applies to them.
+func WithTimeout(timeout time.Duration) Option {
+	return func(client *Client) {
+		client.timeout = timeout
+	}
+}
+
+// WithUserAgent sets the User-Agent header sent with each request.
+func WithUserAgent(userAgent string) Option {
+	return func(client *Client) {
+		client.userAgent = userAgent
+	}
+}
+
+// WithBaseURL sends requests to the given root URL instead of https://api.enigma.io.
+// Useful to go through a proxy, or to target a staging or mock server.
+func WithBaseURL(baseURL string) Option {
+	return func(client *Client) {
+		client.root = strings.TrimRight(baseURL, "/")
+	}
+}
+
+// WithVersion overrides the version of the API targeted by the client. Defaults to "v2".
+func WithVersion(version string) Option {
+	return func(client *Client) {
+		client.version = strings.Trim(version, "/")
+	}
+}
+
+// WithExportPolling sets how often the client checks whether an exported file is ready,
+// and how long it waits before giving up. Defaults to every 10 seconds, for up to 2 minutes.
+func WithExportPolling(interval, timeout time.Duration) Option {
+	return func(client *Client) {
+		if interval > 0 {
+			client.pollingInterval = interval
+		}
+		if timeout > 0 {
+			client.pollingTimeout = timeout
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package enigma
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestWithHTTPClient(t *testing.T) {
+	var called bool
+	httpClient := &http.Client{
+		Transport: roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			called = true
+			return http.DefaultTransport.RoundTrip(req)
+		}),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(key, WithHTTPClient(httpClient), WithBaseURL(server.URL))
+	if _, err := c.Data(datapath).Results(); err != nil {
+		t.Fatal(err)
+	}
+	if !called {
+		t.Fatal("Request was not sent through the provided http.Client")
+	}
+}
+
+func TestWithTimeout(t *testing.T) {
+	httpClient := &http.Client{}
+	c := NewClient(key, WithHTTPClient(httpClient), WithTimeout(time.Second))
+	if c.httpClient.Timeout != time.Second {
+		t.Fatal("Timeout was not applied to the client")
+	}
+	if httpClient.Timeout != 0 {
+		t.Fatal("Provided http.Client should not be modified")
+	}
+}
+
+func TestWithUserAgent(t *testing.T) {
+	var userAgent string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent = r.UserAgent()
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(key, WithUserAgent("enigma-test"), WithBaseURL(server.URL))
+	if _, err := c.Data(datapath).Results(); err != nil {
+		t.Fatal(err)
+	}
+	if userAgent != "enigma-test" {
+		t.Fatal(userAgent)
+	}
+}
+
+func TestWithBaseURL(t *testing.T) {
+	var path string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL+"/"))
+	if _, err := c.Meta().Table(datapath); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/v2/meta/"+key+"/"+datapath {
+		t.Fatal(path)
+	}
+}
+
+func TestWithVersion(t *testing.T) {
+	query := NewClient(key, WithBaseURL("http://localhost:8080"), WithVersion("v3")).Stats(datapath, "column")
+	uri := buildURL(query.baseURI, query.datapath, query.params)
+	if uri != "http://localhost:8080/v3/stats/"+key+"/"+datapath+"?select=column" {
+		t.Fatal(uri)
+	}
+}
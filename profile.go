@@ -0,0 +1,114 @@
+package enigma
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// profileTopN is the number of most frequent values reported for each column of a profile.
+	profileTopN = 10
+	// profileConcurrency bounds the number of stats queries run at once by a profile,
+	// unless the client has a concurrency cap set with WithMaxInFlight.
+	profileConcurrency = 4
+)
+
+// ColumnProfile summarizes the values of a column.
+type ColumnProfile struct {
+	Column  Column
+	Numeric *NumericStats    // Set for numeric columns.
+	Dates   *DateStats       // Set for date columns.
+	Top     []FrequencyEntry // Most frequent values of the column.
+}
+
+// TableProfile summarizes the values of every column of a table.
+type TableProfile struct {
+	Datapath string
+	Columns  []ColumnProfile // In the order of the columns of the table.
+}
+
+// Profile computes statistics on every column of the table at datapath: numeric operations
+// for numeric columns, min and max for dates, and the 10 most frequent values of all columns.
+//
+// One stats query is sent per column, concurrently, within the concurrency cap of the client.
+// The first error encountered interrupts the profile.
+func (client *Client) Profile(datapath string) (*TableProfile, error) {
+	return client.ProfileContext(context.Background(), datapath)
+}
+
+// ProfileContext is like Profile but aborts the requests when ctx is done.
+func (client *Client) ProfileContext(ctx context.Context, datapath string) (*TableProfile, error) {
+	table, err := client.table(ctx, datapath)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	concurrency := profileConcurrency
+	if client.limiter.slots != nil {
+		concurrency = cap(client.limiter.slots)
+	}
+
+	var (
+		profile = &TableProfile{Datapath: datapath, Columns: make([]ColumnProfile, len(table.Result.Columns))}
+		slots   = make(chan struct{}, concurrency)
+		wg      sync.WaitGroup
+		once    sync.Once
+		first   error
+	)
+	for i, column := range table.Result.Columns {
+		wg.Add(1)
+		go func(i int, column Column) {
+			defer wg.Done()
+			select {
+			case slots <- struct{}{}:
+				defer func() { <-slots }()
+			case <-ctx.Done():
+				return
+			}
+
+			if err := client.profileColumn(ctx, datapath, column, &profile.Columns[i]); err != nil {
+				once.Do(func() {
+					first = err
+					cancel()
+				})
+			}
+		}(i, column)
+	}
+	wg.Wait()
+
+	if first != nil {
+		return nil, first
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// profileColumn runs the stats query profiling a single column. The API runs all the operations
+// supported by the type of the column when none is specified.
+func (client *Client) profileColumn(ctx context.Context, datapath string, column Column, profile *ColumnProfile) error {
+	response, err := client.Stats(datapath, column.ID).Limit(profileTopN).Sort(Desc).ResultsContext(ctx)
+	if err != nil {
+		return err
+	}
+	if response.Column().Type == "" {
+		response.Info.Column = map[string]interface{}{"id": column.ID, "type": column.Type}
+	}
+	result, err := response.Decode()
+	if err != nil {
+		return err
+	}
+
+	profile.Column = column
+	profile.Numeric = result.Numeric
+	profile.Dates = result.Dates
+	profile.Top = result.Frequency
+	if len(profile.Top) > profileTopN {
+		profile.Top = profile.Top[:profileTopN]
+	}
+	return nil
+}
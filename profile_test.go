@@ -0,0 +1,70 @@
+package enigma
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func newProfileServer(t *testing.T, failing string) *httptest.Server {
+	results := map[string]string{
+		"namefull":       `{"frequency": [{"namefull": "Smith", "count": "3"}]}`,
+		"total_people":   `{"sum": "10", "avg": "2.5", "max": "4", "min": "1", "frequency": [{"total_people": "1", "count": "2"}]}`,
+		"ratio":          `{"sum": "1", "frequency": []}`,
+		"appt_made_date": `{"min": "2013-01-01", "max": "2013-12-31", "frequency": [{"appt_made_date": "2013-01-01", "count": "4"}]}`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/meta/") {
+			columns, _ := json.Marshal(testColumns)
+			fmt.Fprintf(w, `{"result": {"columns": %s}}`, columns)
+			return
+		}
+
+		query := r.URL.Query()
+		column := query.Get("select")
+		if query.Get("limit") != "10" || query.Get("sort") != "-" || query.Get("operation") != "" {
+			t.Error("Unexpected query", query)
+		}
+		if column == failing {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		fmt.Fprintf(w, `{"result": %s, "info": {"column": "%s"}}`, results[column], column)
+	}))
+}
+
+func TestProfile(t *testing.T) {
+	server := newProfileServer(t, "")
+	defer server.Close()
+
+	profile, err := NewClient(key, WithBaseURL(server.URL), WithMaxInFlight(2)).Profile(datapath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Datapath != datapath || len(profile.Columns) != len(testColumns) {
+		t.Fatalf("%+v", profile)
+	}
+
+	names, people, dates := profile.Columns[0], profile.Columns[1], profile.Columns[3]
+	if names.Column.ID != "namefull" || names.Numeric != nil || names.Dates != nil || len(names.Top) != 1 || names.Top[0].Count != 3 {
+		t.Fatalf("%+v", names)
+	}
+	if people.Numeric == nil || people.Numeric.Sum != 10 || people.Numeric.Max != 4 || people.Dates != nil || len(people.Top) != 1 {
+		t.Fatalf("%+v", people)
+	}
+	if dates.Dates == nil || dates.Dates.Max.Month() != 12 || dates.Numeric != nil {
+		t.Fatalf("%+v", dates)
+	}
+}
+
+func TestProfileError(t *testing.T) {
+	server := newProfileServer(t, "ratio")
+	defer server.Close()
+
+	if _, err := NewClient(key, WithBaseURL(server.URL)).Profile(datapath); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+}
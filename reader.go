@@ -0,0 +1,153 @@
+package enigma
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// ExportReader streams the rows of an exported CSV file, one at a time, without
+// loading the file in memory.
+//
+//    r, err := client.Export("us.gov.whitehouse.visitor-list").Reader(ctx)
+//    if err != nil {
+//        fmt.Println(err)
+//        return
+//    }
+//    defer r.Close()
+//    for r.Next() {
+//        var visit Visit
+//        if err := r.Decode(&visit); err != nil {
+//            fmt.Println(err)
+//            return
+//        }
+//    }
+//    if err := r.Err(); err != nil {
+//        fmt.Println(err)
+//    }
+type ExportReader struct {
+	csv     *csv.Reader
+	closer  io.Closer
+	decoder *RowDecoder
+	header  []string
+	record  []string
+	err     error
+}
+
+// NewExportReader returns an ExportReader reading the CSV content of r, which is decompressed
+// if gzipped. The first line must hold the IDs of the columns. Values are converted according
+// to the given columns, which can be nil.
+func NewExportReader(r io.Reader, columns []Column) (*ExportReader, error) {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	if magic, err := br.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		src = gz
+	}
+
+	er := &ExportReader{
+		csv:     csv.NewReader(src),
+		decoder: NewRowDecoder(columns),
+	}
+	er.csv.ReuseRecord = true
+
+	header, err := er.csv.Read()
+	if err == io.EOF {
+		return nil, errors.New("enigma: missing header in exported file")
+	}
+	if err != nil {
+		return nil, err
+	}
+	er.header = append([]string(nil), header...)
+	if len(er.header) > 0 {
+		er.header[0] = strings.TrimPrefix(er.header[0], "\ufeff")
+	}
+	return er, nil
+}
+
+// Reader requests the export, waits for the file to be ready, and returns an ExportReader
+// streaming its rows. Values are converted according to the metadata of the table,
+// fetched on first use and cached by the client. The reader must be closed by the caller.
+func (q *ExportQuery) Reader(ctx context.Context) (*ExportReader, error) {
+	table, err := q.client.table(ctx, q.datapath)
+	if err != nil {
+		return nil, err
+	}
+	body, err := q.Download(ctx)
+	if err != nil {
+		return nil, err
+	}
+	r, err := NewExportReader(body, table.Result.Columns)
+	if err != nil {
+		body.Close()
+		return nil, err
+	}
+	r.closer = body
+	return r, nil
+}
+
+// Header returns the IDs of the columns of the file.
+func (r *ExportReader) Header() []string {
+	return r.header
+}
+
+// Next advances to the next row. It returns false at the end of the file, or when an error occurred.
+func (r *ExportReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+	r.record, r.err = r.csv.Read()
+	if r.err == io.EOF {
+		r.err = nil
+		r.record = nil
+		return false
+	}
+	return r.err == nil
+}
+
+// Record returns the raw values of the current row, in the order of Header.
+// The slice is only valid until the next call to Next.
+func (r *ExportReader) Record() []string {
+	return r.record
+}
+
+// Decode stores the values of the current row into v, a pointer to a struct or a map,
+// following the rules described on RowDecoder.
+func (r *ExportReader) Decode(v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return errors.New("enigma: Decode expects a non-nil pointer")
+	}
+	if r.record == nil {
+		return errors.New("enigma: Decode called without a current row")
+	}
+
+	values := make(map[string]*string, len(r.header))
+	for i, column := range r.header {
+		if i < len(r.record) {
+			values[column] = &r.record[i]
+		}
+	}
+	return r.decoder.decodeRecord(values, rv.Elem())
+}
+
+// Err returns the error, if any, that interrupted the reading.
+func (r *ExportReader) Err() error {
+	return r.err
+}
+
+// Close releases the underlying download, if any.
+func (r *ExportReader) Close() error {
+	if r.closer == nil {
+		return nil
+	}
+	return r.closer.Close()
+}
@@ -0,0 +1,112 @@
+package enigma
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type exportedVisit struct {
+	Name   string    `enigma:"namefull"`
+	People int64     `enigma:"total_people"`
+	Date   time.Time `enigma:"appt_made_date"`
+}
+
+func TestExportReader(t *testing.T) {
+	for _, content := range [][]byte{[]byte("\ufeff" + testCSV), gzipped(testCSV)} {
+		r, err := NewExportReader(bytes.NewReader(content), testColumns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Join(r.Header(), ",") != "namefull,total_people,appt_made_date" {
+			t.Fatal(r.Header())
+		}
+
+		var visits []exportedVisit
+		for r.Next() {
+			var visit exportedVisit
+			if err := r.Decode(&visit); err != nil {
+				t.Fatal(err)
+			}
+			visits = append(visits, visit)
+		}
+		if err := r.Err(); err != nil {
+			t.Fatal(err)
+		}
+		if len(visits) != 2 || visits[1].Name != "Doe" || visits[1].People != 12 || visits[1].Date.Day() != 15 {
+			t.Fatalf("%+v", visits)
+		}
+	}
+}
+
+func TestExportReaderMap(t *testing.T) {
+	r, err := NewExportReader(strings.NewReader("namefull,total_people\nSmith,\n"), testColumns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Next() {
+		t.Fatal(r.Err())
+	}
+	var row map[string]interface{}
+	if err := r.Decode(&row); err != nil {
+		t.Fatal(err)
+	}
+	if row["namefull"] != "Smith" || row["total_people"] != nil {
+		t.Fatal(row)
+	}
+	if r.Next() || r.Err() != nil {
+		t.Fatal("Expected the end of the file", r.Err())
+	}
+}
+
+func TestExportReaderErrors(t *testing.T) {
+	if _, err := NewExportReader(strings.NewReader(""), nil); err == nil {
+		t.Fatal("Expected error was not returned for a missing header")
+	}
+
+	r, err := NewExportReader(strings.NewReader("a,b\n1,2,3\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Next() || r.Err() == nil {
+		t.Fatal("Expected error was not returned for a malformed row")
+	}
+}
+
+func TestExportQueryReader(t *testing.T) {
+	export := newExportServer(testCSV, 0)
+	defer export.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/meta/") {
+			columns, _ := json.Marshal(testColumns)
+			fmt.Fprintf(w, `{"result": {"columns": %s}}`, columns)
+			return
+		}
+		export.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	r, err := NewClient(key, WithBaseURL(server.URL)).Export(datapath).Reader(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var rows []map[string]interface{}
+	for r.Next() {
+		var row map[string]interface{}
+		if err := r.Decode(&row); err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, row)
+	}
+	if r.Err() != nil || len(rows) != 2 || rows[0]["total_people"] != int64(5) {
+		t.Fatal(r.Err(), rows)
+	}
+}
@@ -0,0 +1,236 @@
+package enigma
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// maxStalledResumes is the number of attempts in a row that may fail to extend the partial
+	// file before a download is abandoned.
+	maxStalledResumes = 3
+	// maxResumes is the total number of attempts made to download a file.
+	maxResumes = 10
+)
+
+// transferError is an interruption of a download that can be resumed.
+type transferError struct {
+	err error
+}
+
+func (e *transferError) Error() string {
+	return e.err.Error()
+}
+
+func (e *transferError) Unwrap() error {
+	return e.err
+}
+
+// fetch downloads the file at uri into the file at part, resuming the transfer from where
+// a previous attempt, possibly made by another process, left it.
+// Interrupted transfers are resumed, after a backoff, as long as they extend the partial file,
+// up to maxResumes attempts in total.
+func (client *Client) fetch(ctx context.Context, uri, part string) error {
+	policy := client.retry
+	if policy == nil {
+		policy = &RetryPolicy{}
+	}
+
+	for attempt, stalled := 1, 0; ; attempt++ {
+		before := fileSize(part)
+		err := client.fetchRange(ctx, uri, part)
+		var interrupted *transferError
+		if err == nil || !errors.As(err, &interrupted) || ctx.Err() != nil {
+			return err
+		}
+		// A server ignoring ranges sends the whole file again: only a larger partial file is progress.
+		if fileSize(part) > before {
+			stalled = 0
+		} else {
+			stalled++
+		}
+		if stalled >= maxStalledResumes || attempt >= maxResumes {
+			return interrupted.err
+		}
+
+		timer := time.NewTimer(policy.backoff(stalled+1, nil))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
+
+// fileSize returns the size of the file at path, or 0 if it cannot be read.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
+
+// fetchRange sends a single request for the bytes of the file at uri missing from part,
+// and writes them to it.
+//
+// The partial file carries the Last-Modified date of the remote file, sent back in an If-Range
+// header: servers respond with the whole file when it changed, or when they do not support
+// ranges, in which case the partial file is overwritten.
+func (client *Client) fetchRange(ctx context.Context, uri, part string) error {
+	f, err := os.OpenFile(part, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	offset := info.Size()
+	// Asking for the file as is prevents the transport from decompressing it on the fly,
+	// so that offsets match the bytes served.
+	header := http.Header{"Accept-Encoding": {"identity"}}
+	if offset > 0 {
+		header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		header.Set("If-Range", info.ModTime().UTC().Format(http.TimeFormat))
+	}
+	resp, err := client.sendFile(ctx, uri, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	size := int64(-1)
+	switch resp.StatusCode {
+	case http.StatusOK:
+		offset, size = 0, resp.ContentLength
+	case http.StatusPartialContent:
+		start, total, ok := contentRange(resp.Header.Get("Content-Range"))
+		if !ok || start != offset {
+			return restart(f, fmt.Errorf("enigma: unexpected Content-Range %q", resp.Header.Get("Content-Range")))
+		}
+		size = total
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The partial file is either complete, or larger than the remote file.
+		if _, total, ok := contentRange(resp.Header.Get("Content-Range")); ok && total == offset {
+			return nil
+		}
+		return restart(f, errors.New("enigma: partial download does not match the remote file"))
+	default:
+		body, _ := ioutil.ReadAll(resp.Body)
+		return newAPIError(client.key, uri, resp, body)
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		return err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return err
+	}
+	body := &bodyReader{Reader: resp.Body}
+	written, err := io.Copy(f, body)
+	if modTime, perr := http.ParseTime(resp.Header.Get("Last-Modified")); perr == nil {
+		os.Chtimes(part, modTime, modTime)
+	}
+	switch {
+	case body.err != nil:
+		return &transferError{body.err}
+	case err != nil:
+		return err
+	case size >= 0 && offset+written < size:
+		return &transferError{io.ErrUnexpectedEOF}
+	case size >= 0 && offset+written > size:
+		return restart(f, errors.New("enigma: downloaded file is larger than announced"))
+	}
+	return nil
+}
+
+// restart empties the partial file f, so the next attempt downloads the whole file,
+// and returns err, or the error that prevented the partial file from being emptied.
+func restart(f *os.File, err error) error {
+	if terr := f.Truncate(0); terr != nil {
+		return terr
+	}
+	return err
+}
+
+// contentRange parses the position of the first byte and the total size in a Content-Range header.
+// Unknown values are reported as -1.
+func contentRange(value string) (start, total int64, ok bool) {
+	if !strings.HasPrefix(value, "bytes ") {
+		return 0, 0, false
+	}
+	slash := strings.IndexByte(value, '/')
+	if slash < 0 {
+		return 0, 0, false
+	}
+	rng, size := value[len("bytes "):slash], value[slash+1:]
+
+	start, total = -1, -1
+	var err error
+	if size != "*" {
+		if total, err = strconv.ParseInt(size, 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	if rng != "*" {
+		dash := strings.IndexByte(rng, '-')
+		if dash < 0 {
+			return 0, 0, false
+		}
+		if start, err = strconv.ParseInt(rng[:dash], 10, 64); err != nil {
+			return 0, 0, false
+		}
+	}
+	return start, total, true
+}
+
+// bodyReader records the error, other than io.EOF, that interrupted the reading of a body.
+type bodyReader struct {
+	io.Reader
+	err error
+}
+
+func (r *bodyReader) Read(p []byte) (int, error) {
+	n, err := r.Reader.Read(p)
+	if err != nil && err != io.EOF {
+		r.err = err
+	}
+	return n, err
+}
+
+// unpack decompresses the downloaded file at part to path, and removes it.
+// A corrupted partial file is removed as well, so the next download starts over.
+func unpack(part, path string) error {
+	f, err := os.Open(part)
+	if err != nil {
+		return err
+	}
+	gz, err := gzip.NewReader(f)
+	if err == nil {
+		r := &bodyReader{Reader: gz}
+		if err = writeFile(path, r); err != nil && r.err == nil {
+			// The file could not be written, but the download itself is fine.
+			f.Close()
+			return err
+		}
+	}
+	f.Close()
+
+	if rerr := os.Remove(part); err == nil {
+		err = rerr
+	}
+	return err
+}
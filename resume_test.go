@@ -0,0 +1,208 @@
+package enigma
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTruncatingServer serves file, cutting the connection after limit bytes for the first
+// cuts requests. Range requests are only honoured when ranges is true.
+func newTruncatingServer(file []byte, limit int, cuts int32, ranges bool) (*httptest.Server, func() []string) {
+	var (
+		requests int32
+		seen     = make(chan string, 100)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen <- r.Header.Get("Range")
+		if !ranges {
+			r.Header.Del("Range")
+		}
+		if atomic.AddInt32(&requests, 1) > cuts {
+			http.ServeContent(w, r, "file.csv.gz", testModTime, bytes.NewReader(file))
+			return
+		}
+
+		start := 0
+		if rng := r.Header.Get("Range"); rng != "" {
+			start, _ = strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+			w.Header().Set("Content-Range", "bytes "+strconv.Itoa(start)+"-"+strconv.Itoa(len(file)-1)+"/"+strconv.Itoa(len(file)))
+			w.Header().Set("Content-Length", strconv.Itoa(len(file)-start))
+			w.Header().Set("Last-Modified", testModTime.Format(http.TimeFormat))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", strconv.Itoa(len(file)))
+			w.Header().Set("Last-Modified", testModTime.Format(http.TimeFormat))
+		}
+		end := start + limit
+		if end > len(file) {
+			end = len(file)
+		}
+		w.Write(file[start:end])
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	return server, func() []string {
+		var ranges []string
+		for len(seen) > 0 {
+			ranges = append(ranges, <-seen)
+		}
+		return ranges
+	}
+}
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "enigma")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestResumeDownload(t *testing.T) {
+	file := gzipped(testCSV)
+	server, requests := newTruncatingServer(file, 20, 2, true)
+	defer server.Close()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "visitors.csv")
+	job := NewClient(key, WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond})).newExportJob(datapath, server.URL, server.URL)
+	job.finish(ExportReady, nil)
+	if err := job.DownloadTo(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil || string(content) != testCSV {
+		t.Fatal(err, string(content))
+	}
+	if got := strings.Join(requests(), ","); got != ",bytes=20-,bytes=40-" {
+		t.Fatal("Unexpected ranges requested:", got)
+	}
+	if _, err := os.Stat(path + ".part"); !os.IsNotExist(err) {
+		t.Fatal("The partial file should be removed")
+	}
+}
+
+func TestResumeDownloadWithoutRanges(t *testing.T) {
+	file := gzipped(testCSV)
+	server, _ := newTruncatingServer(file, 20, 1, false)
+	defer server.Close()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	// Leftovers of an unrelated download are overwritten.
+	path := filepath.Join(dir, "visitors.csv")
+	if err := ioutil.WriteFile(path+".part", []byte("garbage"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	job := NewClient(key, WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond})).newExportJob(datapath, server.URL, server.URL)
+	job.finish(ExportReady, nil)
+	if err := job.DownloadTo(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadFile(path)
+	if err != nil || string(content) != testCSV {
+		t.Fatal(err, string(content))
+	}
+}
+
+func TestResumeDownloadAcrossCalls(t *testing.T) {
+	file := gzipped(testCSV)
+	server, requests := newTruncatingServer(file, 0, 1000, true)
+	defer server.Close()
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	path := filepath.Join(dir, "visitors.csv")
+	c := NewClient(key, WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond}))
+	job := c.newExportJob(datapath, server.URL, server.URL)
+	job.finish(ExportReady, nil)
+
+	// A previous call stopped after the first 30 bytes.
+	if err := ioutil.WriteFile(path+".part", file[:30], 0644); err != nil {
+		t.Fatal(err)
+	}
+	os.Chtimes(path+".part", testModTime, testModTime)
+	if err := job.DownloadTo(context.Background(), path); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+	if got := requests(); len(got) != maxStalledResumes || got[0] != "bytes=30-" {
+		t.Fatal("Unexpected ranges requested:", got)
+	}
+	if info, err := os.Stat(path + ".part"); err != nil || info.Size() != 30 {
+		t.Fatal("The partial file should be kept", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("No file should be created on failure")
+	}
+}
+
+func TestResumeDownloadGivesUp(t *testing.T) {
+	file := gzipped(testCSV)
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	c := NewClient(key, WithRetryPolicy(RetryPolicy{MinBackoff: time.Millisecond}))
+
+	tests := []struct {
+		name     string
+		limit    int
+		ranges   bool
+		requests int
+	}{
+		// Each response without ranges starts over, and never extends the partial file.
+		{"without ranges", 20, false, 1 + maxStalledResumes},
+		// Each response extends the partial file, until the total number of attempts runs out.
+		{"with ranges", 1, true, maxResumes},
+	}
+	for _, test := range tests {
+		server, requests := newTruncatingServer(file, test.limit, 1000, test.ranges)
+		path := filepath.Join(dir, strings.Replace(test.name, " ", "-", -1)+".csv")
+		job := c.newExportJob(datapath, server.URL, server.URL)
+		job.finish(ExportReady, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		err := job.DownloadTo(ctx, path)
+		expired := ctx.Err()
+		cancel()
+		server.Close()
+		if err == nil || expired != nil {
+			t.Fatal(test.name, "Expected the download to give up, got", err)
+		}
+		if got := len(requests()); got != test.requests {
+			t.Fatal(test.name, "Unexpected number of requests:", got)
+		}
+	}
+}
+
+func TestContentRange(t *testing.T) {
+	tests := []struct {
+		value        string
+		start, total int64
+		ok           bool
+	}{
+		{"bytes 10-99/100", 10, 100, true},
+		{"bytes */100", -1, 100, true},
+		{"bytes 10-99/*", 10, -1, true},
+		{"bytes 10/100", 0, 0, false},
+		{"items 10-99/100", 0, 0, false},
+		{"", 0, 0, false},
+	}
+	for _, test := range tests {
+		start, total, ok := contentRange(test.value)
+		if start != test.start || total != test.total || ok != test.ok {
+			t.Fatal(test.value, start, total, ok)
+		}
+	}
+}
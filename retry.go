@@ -0,0 +1,144 @@
+package enigma
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	defaultMinBackoff = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+)
+
+// RetryPolicy describes how requests failing with a transient error are retried.
+// All requests sent by the client are GET or HEAD requests, which makes them safe to retry.
+//
+// A request is retried when it times out, when its connection is reset or closed in the middle
+// of a response, or when the server responds with 429 Too Many Requests or a 5xx status other
+// than 501 Not Implemented.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first one.
+	// Values lower than 2 disable retries.
+	MaxAttempts int
+	// MinBackoff is the delay before the first retry, doubled after each attempt. Defaults to 500ms.
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay between two attempts. Defaults to 30s.
+	MaxBackoff time.Duration
+	// OnRetry, if set, is called before each retry with the number of the attempt that failed,
+	// the delay before the next one, and the error that caused it.
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// WithRetryPolicy retries requests failing with a transient error according to policy.
+// By default, requests are not retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(client *Client) {
+		client.retry = &policy
+	}
+}
+
+// retryable reports whether a request that ended with resp or err may be sent again.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if err != nil {
+		return ctx.Err() == nil && transient(err)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests ||
+		(resp.StatusCode >= 500 && resp.StatusCode != http.StatusNotImplemented)
+}
+
+// transient reports whether err is a failure to reach the server that may not happen again:
+// a timeout, a connection reset, or a connection closed in the middle of a response.
+func transient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// backoff returns the delay to observe after the given failed attempt.
+// The Retry-After header of resp takes precedence over the exponential backoff when present,
+// within the limit of MaxBackoff.
+func (p *RetryPolicy) backoff(attempt int, resp *http.Response) time.Duration {
+	min, max := p.MinBackoff, p.MaxBackoff
+	if min <= 0 {
+		min = defaultMinBackoff
+	}
+	if max <= 0 {
+		max = defaultMaxBackoff
+	}
+
+	if resp != nil {
+		if wait, ok := retryAfter(resp.Header.Get("Retry-After")); ok {
+			if wait > max {
+				wait = max
+			}
+			return wait
+		}
+	}
+
+	wait := min
+	for i := 1; i < attempt && wait < max; i++ {
+		wait *= 2
+	}
+	if wait > max {
+		wait = max
+	}
+	// Jitter spreads the retries of concurrent requests over [wait/2, wait].
+	return wait/2 + time.Duration(rand.Int63n(int64(wait/2)+1))
+}
+
+// retryAfter parses the value of a Retry-After header, expressed either in seconds or as an HTTP date.
+func retryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		wait := time.Until(date)
+		if wait < 0 {
+			wait = 0
+		}
+		return wait, true
+	}
+	return 0, false
+}
+
+// withRetries calls do until it succeeds, fails with a permanent error, or the policy
+// runs out of attempts. The response of the last attempt is returned as is.
+func (client *Client) withRetries(ctx context.Context, policy *RetryPolicy, uri string, do func() (*http.Response, error)) (*http.Response, error) {
+	for attempt := 1; ; attempt++ {
+		resp, err := do()
+		if policy == nil || attempt >= policy.MaxAttempts || !retryable(ctx, resp, err) {
+			return resp, err
+		}
+
+		wait := policy.backoff(attempt, resp)
+		if resp != nil {
+			body, _ := ioutil.ReadAll(resp.Body)
+			resp.Body.Close()
+			err = newAPIError(client.key, uri, resp, body)
+		}
+		if policy.OnRetry != nil {
+			policy.OnRetry(attempt, wait, err)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		}
+	}
+}
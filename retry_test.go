@@ -0,0 +1,189 @@
+package enigma
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// newFlakyServer fails the first failures requests with the given status.
+func newFlakyServer(failures int32, status int) (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= failures {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+	return server, &requests
+}
+
+func TestRetryPolicy(t *testing.T) {
+	server, requests := newFlakyServer(2, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	var retries []int
+	c := NewClient(key, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{
+		MaxAttempts: 3,
+		OnRetry: func(attempt int, wait time.Duration, err error) {
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+				t.Error("Unexpected error passed to OnRetry:", err)
+			}
+			retries = append(retries, attempt)
+		},
+	}))
+	if _, err := c.Data(datapath).Results(); err != nil {
+		t.Fatal(err)
+	}
+	if *requests != 3 || len(retries) != 2 || retries[0] != 1 || retries[1] != 2 {
+		t.Fatal("Unexpected retries", *requests, retries)
+	}
+}
+
+func TestRetryPolicyInterruptedBody(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) > 1 {
+			w.Write([]byte(`{}`))
+			return
+		}
+		// The connection is closed in the middle of the body.
+		w.Header().Set("Content-Length", "100")
+		w.Write([]byte(`{"result": [`))
+		w.(http.Flusher).Flush()
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond}))
+	if _, err := c.Data(datapath).Results(); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Fatal("Expected 2 requests, got", n)
+	}
+}
+
+func TestRetryPolicyExhausted(t *testing.T) {
+	server, requests := newFlakyServer(5, http.StatusTooManyRequests)
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 2}))
+	_, err := c.Data(datapath).Results()
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatal("Expected the last error to be returned, got", err)
+	}
+	if *requests != 2 {
+		t.Fatal(*requests)
+	}
+}
+
+func TestRetryPolicyPermanentError(t *testing.T) {
+	server, requests := newFlakyServer(1, http.StatusBadRequest)
+	defer server.Close()
+
+	c := NewClient(key, WithBaseURL(server.URL), WithRetryPolicy(RetryPolicy{MaxAttempts: 3}))
+	if _, err := c.Data(datapath).Results(); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+	if *requests != 1 {
+		t.Fatal("Permanent errors should not be retried")
+	}
+}
+
+func TestRetryDisabledByDefault(t *testing.T) {
+	server, requests := newFlakyServer(1, http.StatusBadGateway)
+	defer server.Close()
+
+	if _, err := NewClient(key, WithBaseURL(server.URL)).Data(datapath).Results(); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+	if *requests != 1 {
+		t.Fatal(*requests)
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{MinBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, max := range []time.Duration{0, 100, 200, 400, 800, 1000, 1000} {
+		if attempt == 0 {
+			continue
+		}
+		max *= time.Millisecond
+		if wait := policy.backoff(attempt, nil); wait < max/2 || wait > max {
+			t.Fatal(attempt, wait)
+		}
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": {"7"}}}
+	if wait := policy.backoff(1, resp); wait != time.Second {
+		t.Fatal("Retry-After should be capped by MaxBackoff", wait)
+	}
+	policy.MaxBackoff = 10 * time.Second
+	if wait := policy.backoff(1, resp); wait != 7*time.Second {
+		t.Fatal("Retry-After was not honoured", wait)
+	}
+	resp.Header.Set("Retry-After", "86400")
+	if wait := (&RetryPolicy{}).backoff(1, resp); wait != defaultMaxBackoff {
+		t.Fatal("Retry-After should be capped by the default MaxBackoff", wait)
+	}
+}
+
+type timeoutError struct{}
+
+func (timeoutError) Error() string   { return "i/o timeout" }
+func (timeoutError) Timeout() bool   { return true }
+func (timeoutError) Temporary() bool { return true }
+
+func TestRetryableErrors(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		err       error
+		retryable bool
+	}{
+		{&url.Error{Op: "Get", URL: "/", Err: &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}}, true},
+		{&url.Error{Op: "Get", URL: "/", Err: timeoutError{}}, true},
+		{&url.Error{Op: "Get", URL: "/", Err: io.ErrUnexpectedEOF}, true},
+		{&url.Error{Op: "Get", URL: "/", Err: x509.UnknownAuthorityError{}}, false},
+		{&url.Error{Op: "Get", URL: "/", Err: errors.New(`unsupported protocol scheme "ftp"`)}, false},
+	}
+	for _, test := range tests {
+		if retryable(ctx, nil, test.err) != test.retryable {
+			t.Fatal(test.err, !test.retryable)
+		}
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if retryable(canceled, nil, &url.Error{Op: "Get", URL: "/", Err: timeoutError{}}) {
+		t.Fatal("Requests should not be retried once their context is done")
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	if wait, ok := retryAfter("120"); !ok || wait != 2*time.Minute {
+		t.Fatal(wait, ok)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if wait, ok := retryAfter(date); !ok || wait <= 59*time.Minute {
+		t.Fatal(wait, ok)
+	}
+	if _, ok := retryAfter("soon"); ok {
+		t.Fatal("Invalid values should be ignored")
+	}
+}
@@ -0,0 +1,71 @@
+package enigma
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SearchField is a field to which a search can be restricted. See Field.
+type SearchField string
+
+// Field restricts a search to the given field:
+//    enigma.Field("namefull").Matches("smith").Or(enigma.Field("namelast").Matches("jones"))
+func Field(name string) SearchField {
+	return SearchField(name)
+}
+
+// Matches searches the field for term.
+func (f SearchField) Matches(term string) SearchClause {
+	if !validColumn.MatchString(string(f)) {
+		return SearchClause{err: fmt.Errorf("enigma: invalid search field %q", string(f))}
+	}
+	clause := Match(term)
+	if clause.err == nil {
+		clause.terms[0] = "@" + string(f) + " " + clause.terms[0]
+	}
+	return clause
+}
+
+// Match searches the entire table for term.
+//
+// The search syntax provides no way to escape its operators: | is replaced by a space in term,
+// as well as any leading @, so that user input never alters the meaning of the search.
+func Match(term string) SearchClause {
+	term = strings.Replace(term, "|", " ", -1)
+	term = strings.Join(strings.Fields(strings.TrimLeft(term, "@ \t\n")), " ")
+	if term == "" {
+		return SearchClause{err: errors.New("enigma: empty search term")}
+	}
+	return SearchClause{terms: []string{term}}
+}
+
+// SearchClause is a search built with Match or Field, to be passed to the SearchClause
+// method of DataQuery, StatsQuery and ExportQuery.
+type SearchClause struct {
+	terms []string
+	err   error
+}
+
+// Or matches rows matching the clause or any of the others.
+func (s SearchClause) Or(others ...SearchClause) SearchClause {
+	clause := SearchClause{terms: append([]string(nil), s.terms...), err: s.err}
+	for _, other := range others {
+		if clause.err == nil {
+			clause.err = other.err
+		}
+		clause.terms = append(clause.terms, other.terms...)
+	}
+	return clause
+}
+
+// Err returns the error, if any, that made the clause invalid.
+// Invalid clauses make queries fail with that error.
+func (s SearchClause) Err() error {
+	return s.err
+}
+
+// String returns the clause in the format expected by the API.
+func (s SearchClause) String() string {
+	return strings.Join(s.terms, "|")
+}
@@ -0,0 +1,53 @@
+package enigma
+
+import "testing"
+
+func TestSearchClause(t *testing.T) {
+	clauses := map[string]SearchClause{
+		"smith":                               Match("smith"),
+		"@namefull smith":                     Field("namefull").Matches("smith"),
+		"@namefull smith|@namelast jones":     Field("namefull").Matches("smith").Or(Field("namelast").Matches("jones")),
+		"@namefull a b|c":                     Field("namefull").Matches("a|b").Or(Match("@c")),
+		"john@example.com":                    Match("  john@example.com "),
+		"@namefull smith|jones|@namelast doe": Field("namefull").Matches("smith").Or(Match("jones"), Field("namelast").Matches("doe")),
+	}
+	for expected, clause := range clauses {
+		if err := clause.Err(); err != nil {
+			t.Fatal(expected, err)
+		}
+		if clause.String() != expected {
+			t.Fatal(expected, clause.String())
+		}
+	}
+}
+
+func TestSearchClauseErrors(t *testing.T) {
+	for _, clause := range []SearchClause{
+		Field("name full").Matches("smith"),
+		Field("@namefull").Matches("smith"),
+		Match(" | "),
+		Match("smith").Or(Field("").Matches("jones")),
+	} {
+		if clause.Err() == nil {
+			t.Fatal("Expected error was not returned for", clause)
+		}
+	}
+}
+
+func TestQuerySearchClause(t *testing.T) {
+	clause := Field("namefull").Matches("smith")
+	if query := client.Data(datapath).SearchClause(clause); query.params.Get("search") != "@namefull smith" {
+		t.Fatal("Parameter was not properly added to the query")
+	}
+	if query := client.Stats(datapath, "column").SearchClause(clause); query.params.Get("search") != "@namefull smith" {
+		t.Fatal("Parameter was not properly added to the query")
+	}
+	if query := client.Export(datapath).SearchClause(clause); query.params.Get("search") != "@namefull smith" {
+		t.Fatal("Parameter was not properly added to the query")
+	}
+
+	query := client.Data(datapath).SearchClause(Field("name full").Matches("smith"))
+	if _, err := query.Results(); err == nil || err != query.err {
+		t.Fatal("Expected the clause error to be returned, got", err)
+	}
+}
@@ -0,0 +1,107 @@
+package enigma
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// sortTarget is what a SortKey sorts on.
+type sortTarget int
+
+const (
+	sortColumn sortTarget = iota
+	sortResult
+	sortValue
+)
+
+// SortKey is a sorting criterion, passed to the SortBy method of DataQuery, StatsQuery and ExportQuery.
+type SortKey struct {
+	Column    string
+	Direction SortDirection
+	target    sortTarget
+}
+
+// Ascending sorts on column in ascending order.
+func Ascending(column string) SortKey {
+	return SortKey{Column: column, Direction: Asc}
+}
+
+// Descending sorts on column in descending order.
+func Descending(column string) SortKey {
+	return SortKey{Column: column, Direction: Desc}
+}
+
+// ByResult sorts the results of a stats query on the result of the calculation,
+// such as the count of each value of a frequency, or the result of a compound operation.
+func ByResult(direction SortDirection) SortKey {
+	return SortKey{Direction: direction, target: sortResult}
+}
+
+// ByValue sorts the results of a stats query on the values of the selected column.
+func ByValue(direction SortDirection) SortKey {
+	return SortKey{Direction: direction, target: sortValue}
+}
+
+// formatSortKeys returns the value of the sort parameter for the given keys. The selected column
+// of a stats query is substituted to ByValue keys, and ByResult keys are rendered as a direction only.
+// Both are rejected when column is empty, as they only apply to stats queries.
+func formatSortKeys(keys []SortKey, column string) (string, error) {
+	if len(keys) == 0 {
+		return "", errors.New("enigma: no sort key given")
+	}
+
+	formatted := make([]string, len(keys))
+	for i, key := range keys {
+		if key.Direction != Asc && key.Direction != Desc {
+			return "", fmt.Errorf("enigma: invalid sort direction %q", string(key.Direction))
+		}
+		switch key.target {
+		case sortResult, sortValue:
+			if column == "" {
+				return "", errors.New("enigma: ByResult and ByValue only apply to stats queries")
+			}
+			if key.target == sortValue {
+				formatted[i] = column
+			}
+		default:
+			if !validColumn.MatchString(key.Column) {
+				return "", fmt.Errorf("enigma: invalid sort column %q", key.Column)
+			}
+			formatted[i] = key.Column
+		}
+		formatted[i] += string(key.Direction)
+	}
+	return strings.Join(formatted, ","), nil
+}
+
+// SortBy sorts rows on one or more keys, in order of precedence, replacing any sort set previously.
+//    query.SortBy(enigma.Descending("appt_made_date"), enigma.Ascending("namelast"))
+func (q *DataQuery) SortBy(keys ...SortKey) *DataQuery {
+	sort, err := formatSortKeys(keys, "")
+	if err != nil {
+		return (*DataQuery)((*query)(q).fail(err))
+	}
+	return (*DataQuery)((*query)(q).set("sort", sort))
+}
+
+// SortBy sorts results on one or more keys, in order of precedence, replacing any sort set previously.
+// Use ByResult to sort on the result of the calculation, and ByValue to sort on the values of the selected column.
+//    query.SortBy(enigma.ByResult(enigma.Desc), enigma.ByValue(enigma.Asc))
+func (q *StatsQuery) SortBy(keys ...SortKey) *StatsQuery {
+	sort, err := formatSortKeys(keys, q.params.Get("select"))
+	if err != nil {
+		return (*StatsQuery)((*query)(q).fail(err))
+	}
+	return (*StatsQuery)((*query)(q).set("sort", sort))
+}
+
+// SortBy sorts rows on one or more keys, in order of precedence, replacing any sort set previously.
+//    query.SortBy(enigma.Descending("appt_made_date"), enigma.Ascending("namelast"))
+func (q *ExportQuery) SortBy(keys ...SortKey) *ExportQuery {
+	sort, err := formatSortKeys(keys, "")
+	if err != nil {
+		return (*ExportQuery)((*query)(q).fail(err))
+	}
+	return (*ExportQuery)((*query)(q).set("sort", sort))
+}
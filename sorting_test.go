@@ -0,0 +1,37 @@
+package enigma
+
+import "testing"
+
+func TestSortBy(t *testing.T) {
+	data := client.Data(datapath).SortBy(Descending("appt_made_date"), Ascending("namelast"))
+	if data.err != nil || data.params.Get("sort") != "appt_made_date-,namelast+" {
+		t.Fatal(data.err, data.params)
+	}
+
+	export := client.Export(datapath).Sort("x", Asc).SortBy(SortKey{Column: "namelast", Direction: Desc})
+	if export.err != nil || len(export.params["sort"]) != 1 || export.params.Get("sort") != "namelast-" {
+		t.Fatal(export.err, export.params)
+	}
+
+	stats := client.Stats(datapath, "visitee_namelast").SortBy(ByResult(Desc), ByValue(Asc))
+	if stats.err != nil || stats.params.Get("sort") != "-,visitee_namelast+" {
+		t.Fatal(stats.err, stats.params)
+	}
+}
+
+func TestSortByErrors(t *testing.T) {
+	for _, query := range []*DataQuery{
+		client.Data(datapath).SortBy(),
+		client.Data(datapath).SortBy(ByResult(Desc)),
+		client.Data(datapath).SortBy(ByValue(Asc)),
+		client.Data(datapath).SortBy(SortKey{Column: "x", Direction: "up"}),
+		client.Data(datapath).SortBy(Ascending("not a column")),
+	} {
+		if _, err := query.Results(); err == nil || err != query.err {
+			t.Fatal("Expected error was not returned", query.params)
+		}
+	}
+	if query := client.Export(datapath).SortBy(ByValue(Asc)); query.err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+}
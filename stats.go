@@ -0,0 +1,284 @@
+package enigma
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// NumericStats holds the results of the operations run on a numeric column.
+// Fields of operations that were not run are left to zero.
+type NumericStats struct {
+	Sum      float64
+	Avg      float64
+	StdDev   float64
+	Variance float64
+	Max      float64
+	Min      float64
+}
+
+// DateStats holds the results of the operations run on a date column.
+// Fields of operations that were not run are left to zero.
+type DateStats struct {
+	Min time.Time
+	Max time.Time
+}
+
+// FrequencyEntry is a value of a column along with the number of rows holding it.
+type FrequencyEntry struct {
+	Value string
+	Count int64
+}
+
+// CompoundEntry is a value of a column along with the result of a compound operation (see StatsQuery.By).
+type CompoundEntry struct {
+	Value  string
+	Result float64
+}
+
+// StatsResult holds the typed results of a stats query.
+// Only the fields matching the operations the API reports in Info.Operations are set.
+type StatsResult struct {
+	Numeric   *NumericStats    // Set when sum, avg, stddev or variance were computed, or min and max on a numeric column.
+	Dates     *DateStats       // Set when min and max were computed on a date column.
+	Frequency []FrequencyEntry // Set when frequency was computed.
+	Compound  []CompoundEntry  // Set for compound operations.
+}
+
+// Column returns the column the statistics were computed on, as described by Info.Column.
+// Only its ID is set when the API does not describe the column further.
+func (r *StatsResponse) Column() Column {
+	var c Column
+	switch v := r.Info.Column.(type) {
+	case string:
+		c.ID = v
+	case map[string]interface{}:
+		if b, err := json.Marshal(v); err == nil {
+			json.Unmarshal(b, &c)
+		}
+	}
+	return c
+}
+
+// Decode parses Result into typed values, according to the operations listed in Info.Operations
+// and the type of the column.
+//    response, err := client.Stats("us.gov.whitehouse.visitor-list", "total_people").Operation(enigma.Sum).Results()
+//    ...
+//    stats, err := response.Decode()
+//    ...
+//    fmt.Println(stats.Numeric.Sum)
+func (r *StatsResponse) Decode() (*StatsResult, error) {
+	result := &StatsResult{}
+	column := r.Column()
+
+	raw := bytes.TrimSpace(r.Result)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return result, nil
+	}
+	if raw[0] == '[' {
+		entries, err := decodeCompound(raw, column.ID)
+		result.Compound = entries
+		return result, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, err
+	}
+
+	operations := r.Info.Operations
+	if len(operations) == 0 {
+		for key := range fields {
+			operations = append(operations, Operation(key))
+		}
+		sort.Slice(operations, func(i, j int) bool { return operations[i] < operations[j] })
+	}
+
+	dates := isDateStats(column, fields)
+	for _, op := range operations {
+		value, ok := fields[string(op)]
+		if !ok {
+			continue
+		}
+
+		var err error
+		switch {
+		case op == Frequency:
+			result.Frequency, err = decodeFrequency(value, column.ID)
+		case isArray(value):
+			result.Compound, err = decodeCompound(value, column.ID)
+		case dates && (op == Min || op == Max):
+			if result.Dates == nil {
+				result.Dates = &DateStats{}
+			}
+			err = decodeDateStat(value, op, result.Dates)
+		default:
+			if result.Numeric == nil {
+				result.Numeric = &NumericStats{}
+			}
+			err = decodeNumericStat(value, op, result.Numeric)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("enigma: cannot decode %s: %v", op, err)
+		}
+	}
+	return result, nil
+}
+
+// isDateStats reports whether min and max are dates, based on the type of the column when known,
+// or on their values otherwise.
+func isDateStats(column Column, fields map[string]json.RawMessage) bool {
+	if column.Type != "" {
+		return column.Kind() == DateColumn
+	}
+	for _, op := range []Operation{Min, Max} {
+		if s, ok := scalar(fields[string(op)]); ok {
+			if _, err := strconv.ParseFloat(s, 64); err == nil {
+				return false
+			}
+			if _, err := parseDate(s); err == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isArray(raw json.RawMessage) bool {
+	raw = bytes.TrimSpace(raw)
+	return len(raw) > 0 && raw[0] == '['
+}
+
+// scalar returns a string or numeric JSON value as a string. It returns false for null.
+func scalar(raw json.RawMessage) (string, bool) {
+	if len(raw) == 0 {
+		return "", false
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	var v interface{}
+	if dec.Decode(&v) != nil {
+		return "", false
+	}
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func decodeNumericStat(raw json.RawMessage, op Operation, stats *NumericStats) error {
+	s, ok := scalar(raw)
+	if !ok || s == "" {
+		return nil
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return err
+	}
+	switch op {
+	case Sum:
+		stats.Sum = f
+	case Avg:
+		stats.Avg = f
+	case StdDev:
+		stats.StdDev = f
+	case Variance:
+		stats.Variance = f
+	case Max:
+		stats.Max = f
+	case Min:
+		stats.Min = f
+	}
+	return nil
+}
+
+func decodeDateStat(raw json.RawMessage, op Operation, stats *DateStats) error {
+	s, ok := scalar(raw)
+	if !ok || s == "" {
+		return nil
+	}
+	t, err := parseDate(s)
+	if err != nil {
+		return err
+	}
+	if op == Min {
+		stats.Min = t
+	} else {
+		stats.Max = t
+	}
+	return nil
+}
+
+// statsEntries decodes a list of objects holding the value of a column along with a result.
+// The value is read from the key named after the column, or "value", and the result from
+// resultKey when set, or from the other key of the object.
+func statsEntries(raw json.RawMessage, column, resultKey string) (values, results []string, err error) {
+	var entries []map[string]json.RawMessage
+	if err = json.Unmarshal(raw, &entries); err != nil {
+		return
+	}
+	for _, entry := range entries {
+		valueKey := column
+		if _, ok := entry[valueKey]; !ok {
+			valueKey = "value"
+		}
+		key := resultKey
+		if _, ok := entry[key]; !ok || key == "" {
+			for k := range entry {
+				if k != valueKey {
+					key = k
+					break
+				}
+			}
+		}
+		value, _ := scalar(entry[valueKey])
+		result, _ := scalar(entry[key])
+		values = append(values, value)
+		results = append(results, result)
+	}
+	return
+}
+
+func decodeFrequency(raw json.RawMessage, column string) ([]FrequencyEntry, error) {
+	values, counts, err := statsEntries(raw, column, "count")
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]FrequencyEntry, len(values))
+	for i := range values {
+		entries[i].Value = values[i]
+		if counts[i] == "" {
+			continue
+		}
+		if entries[i].Count, err = parseInt(counts[i], 64); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func decodeCompound(raw json.RawMessage, column string) ([]CompoundEntry, error) {
+	values, results, err := statsEntries(raw, column, "")
+	if err != nil {
+		return nil, err
+	}
+	entries := make([]CompoundEntry, len(values))
+	for i := range values {
+		entries[i].Value = values[i]
+		if results[i] == "" {
+			continue
+		}
+		if entries[i].Result, err = strconv.ParseFloat(results[i], 64); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
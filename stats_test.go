@@ -0,0 +1,80 @@
+package enigma
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func decodeStats(t *testing.T, body string) *StatsResult {
+	var response StatsResponse
+	if err := json.Unmarshal([]byte(body), &response); err != nil {
+		t.Fatal(err)
+	}
+	result, err := response.Decode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func TestStatsDecodeNumeric(t *testing.T) {
+	result := decodeStats(t, `{
+		"result": {"sum": "120", "avg": "2.5", "stddev": 1.5, "variance": "2.25", "max": "10", "min": "1",
+			"frequency": [{"total_people": "1", "count": "30"}, {"total_people": "2", "count": 12}]},
+		"info": {"column": {"id": "total_people", "type": "type_numeric"},
+			"operations": ["sum", "avg", "stddev", "variance", "max", "min", "frequency"]}
+	}`)
+
+	expected := NumericStats{Sum: 120, Avg: 2.5, StdDev: 1.5, Variance: 2.25, Max: 10, Min: 1}
+	if result.Numeric == nil || *result.Numeric != expected {
+		t.Fatalf("%+v", result.Numeric)
+	}
+	if result.Dates != nil || result.Compound != nil {
+		t.Fatalf("%+v", result)
+	}
+	if len(result.Frequency) != 2 || result.Frequency[0] != (FrequencyEntry{"1", 30}) || result.Frequency[1] != (FrequencyEntry{"2", 12}) {
+		t.Fatal(result.Frequency)
+	}
+}
+
+func TestStatsDecodeDates(t *testing.T) {
+	result := decodeStats(t, `{
+		"result": {"min": "2009-01-01T00:00:00", "max": "2013-05-14"},
+		"info": {"column": "appt_made_date", "operations": ["min", "max"]}
+	}`)
+
+	if result.Numeric != nil || result.Dates == nil {
+		t.Fatalf("%+v", result)
+	}
+	if !result.Dates.Min.Equal(time.Date(2009, 1, 1, 0, 0, 0, 0, time.UTC)) || !result.Dates.Max.Equal(time.Date(2013, 5, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("%+v", result.Dates)
+	}
+}
+
+func TestStatsDecodeOperationsFilter(t *testing.T) {
+	result := decodeStats(t, `{"result": {"sum": "120", "avg": "2.5"}, "info": {"operations": ["sum"]}}`)
+	if result.Numeric == nil || result.Numeric.Sum != 120 || result.Numeric.Avg != 0 {
+		t.Fatalf("%+v", result.Numeric)
+	}
+}
+
+func TestStatsDecodeCompound(t *testing.T) {
+	for _, body := range []string{
+		`{"result": [{"visitee_namelast": "potus", "sum": "1200"}, {"visitee_namelast": "flotus", "sum": "300.5"}], "info": {"column": "visitee_namelast"}}`,
+		`{"result": {"sum": [{"visitee_namelast": "potus", "sum": "1200"}, {"visitee_namelast": "flotus", "sum": "300.5"}]}, "info": {"column": "visitee_namelast", "operations": ["sum"]}}`,
+	} {
+		result := decodeStats(t, body)
+		if len(result.Compound) != 2 || result.Compound[0] != (CompoundEntry{"potus", 1200}) || result.Compound[1] != (CompoundEntry{"flotus", 300.5}) {
+			t.Fatalf("%+v", result)
+		}
+	}
+}
+
+func TestStatsDecodeError(t *testing.T) {
+	var response StatsResponse
+	json.Unmarshal([]byte(`{"result": {"sum": "lots"}, "info": {"operations": ["sum"]}}`), &response)
+	if _, err := response.Decode(); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+}
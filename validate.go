@@ -0,0 +1,229 @@
+package enigma
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// whereColumn extracts the column and the operator of a where clause.
+var whereColumn = regexp.MustCompile(`^\s*([A-Za-z0-9_]+)\s*(?:(>=|<=|!=|>|<|=)|\s(?:not\s+)?(in|between)\s)`)
+
+// searchField extracts the field a search is restricted to.
+var searchField = regexp.MustCompile(`^@(\S+)`)
+
+// tableCache holds the metadata of the tables validated by a client.
+type tableCache struct {
+	mu     sync.Mutex
+	tables map[string]*MetaTableNodeResponse
+}
+
+// table returns the metadata of the table at datapath, fetching it only on first use.
+func (client *Client) table(ctx context.Context, datapath string) (*MetaTableNodeResponse, error) {
+	client.tables.mu.Lock()
+	table, ok := client.tables.tables[datapath]
+	client.tables.mu.Unlock()
+	if ok {
+		return table, nil
+	}
+
+	table, err := client.Meta().TableContext(ctx, datapath)
+	if err != nil {
+		return nil, err
+	}
+
+	client.tables.mu.Lock()
+	defer client.tables.mu.Unlock()
+	if client.tables.tables == nil {
+		client.tables.tables = make(map[string]*MetaTableNodeResponse)
+	}
+	client.tables.tables[datapath] = table
+	return table, nil
+}
+
+// ValidationError lists the problems found by Validate in a query.
+type ValidationError struct {
+	Datapath string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("enigma: invalid query on %s: %s", e.Datapath, strings.Join(e.Problems, "; "))
+}
+
+// validator checks the parameters of a query against the columns of a table.
+type validator struct {
+	datapath string
+	columns  map[string]Column
+	problems []string
+}
+
+func newValidator(ctx context.Context, q *query) (*validator, error) {
+	if q.err != nil {
+		return nil, q.err
+	}
+	table, err := q.client.table(ctx, q.datapath)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{datapath: q.datapath, columns: make(map[string]Column)}
+	for _, c := range table.Result.Columns {
+		v.columns[c.ID] = c
+	}
+	return v, nil
+}
+
+func (v *validator) addProblem(format string, args ...interface{}) {
+	v.problems = append(v.problems, fmt.Sprintf(format, args...))
+}
+
+// column returns the column with the given ID, reporting a problem if it does not exist.
+func (v *validator) column(id, parameter string) (Column, bool) {
+	c, ok := v.columns[id]
+	if !ok {
+		v.addProblem("unknown column %q in %s", id, parameter)
+	}
+	return c, ok
+}
+
+// err returns a *ValidationError if any problem was found.
+func (v *validator) err() error {
+	if len(v.problems) == 0 {
+		return nil
+	}
+	return &ValidationError{Datapath: v.datapath, Problems: v.problems}
+}
+
+// checkSelect checks that selected columns exist.
+func (v *validator) checkSelect(params url.Values) {
+	for _, value := range params["select"] {
+		for _, id := range strings.Split(value, ",") {
+			v.column(id, "select")
+		}
+	}
+}
+
+// checkSort checks that sorted columns exist.
+func (v *validator) checkSort(params url.Values) {
+	for _, value := range params["sort"] {
+		for _, key := range strings.Split(value, ",") {
+			id := strings.TrimRight(key, string(Asc)+string(Desc))
+			if id == "" {
+				v.addProblem("missing column in sort %q", key)
+				continue
+			}
+			v.column(id, "sort")
+		}
+	}
+}
+
+// checkWhere checks that where clauses apply to existing numeric or date columns.
+func (v *validator) checkWhere(params url.Values) {
+	for _, clause := range params["where"] {
+		m := whereColumn.FindStringSubmatch(clause)
+		if m == nil {
+			v.addProblem("invalid where clause %q", clause)
+			continue
+		}
+		c, ok := v.column(m[1], "where")
+		if ok && c.Kind() == StringColumn {
+			operator := m[2] + m[3]
+			v.addProblem("operator %q cannot be used on %s column %q, use Search() instead", operator, c.Kind(), c.ID)
+		}
+	}
+}
+
+// checkSearch checks that searches restricted to a field target existing columns.
+func (v *validator) checkSearch(params url.Values) {
+	for _, search := range params["search"] {
+		for _, term := range strings.Split(search, "|") {
+			if m := searchField.FindStringSubmatch(term); m != nil {
+				v.column(m[1], "search")
+			}
+		}
+	}
+}
+
+// validOperation reports whether op can be run on columns of the given kind.
+func validOperation(kind ColumnKind, op Operation) bool {
+	switch kind {
+	case NumericColumn:
+		switch op {
+		case Sum, Avg, StdDev, Variance, Max, Min, Frequency:
+			return true
+		}
+	case DateColumn:
+		return op == Max || op == Min || op == Frequency
+	default:
+		return op == Frequency
+	}
+	return false
+}
+
+// Validate checks the query against the metadata of the table, fetched on first use and cached
+// by the client, without sending it. It returns a *ValidationError listing every unknown column
+// and where clause applied to a column that is neither numeric nor a date.
+func (q *DataQuery) Validate(ctx context.Context) error {
+	v, err := newValidator(ctx, (*query)(q))
+	if err != nil {
+		return err
+	}
+	v.checkSelect(q.params)
+	v.checkSort(q.params)
+	v.checkWhere(q.params)
+	v.checkSearch(q.params)
+	return v.err()
+}
+
+// Validate checks the query against the metadata of the table, fetched on first use and cached
+// by the client, without sending it. It returns a *ValidationError listing every unknown column,
+// where clause applied to a column that is neither numeric nor a date, and operation that does
+// not apply to the type of the selected column.
+func (q *StatsQuery) Validate(ctx context.Context) error {
+	v, err := newValidator(ctx, (*query)(q))
+	if err != nil {
+		return err
+	}
+	v.checkWhere(q.params)
+	v.checkSearch(q.params)
+
+	column, ok := v.column(q.params.Get("select"), "select")
+	for _, op := range q.params["operation"] {
+		if ok && !validOperation(column.Kind(), Operation(op)) {
+			v.addProblem("operation %q cannot be run on %s column %q", op, column.Kind(), column.ID)
+		}
+	}
+
+	by, of := q.params.Get("by"), q.params.Get("of")
+	if by != "" && by != string(Sum) && by != string(Avg) {
+		v.addProblem("invalid compound operation %q, must be sum or avg", by)
+	}
+	switch {
+	case by != "" && of == "":
+		v.addProblem("compound operation %q requires Of()", by)
+	case of != "":
+		if c, ok := v.column(of, "of"); ok && c.Kind() != NumericColumn {
+			v.addProblem("compound operations require a numeric column, %q is a %s column", of, c.Kind())
+		}
+	}
+	return v.err()
+}
+
+// Validate checks the query against the metadata of the table, fetched on first use and cached
+// by the client, without sending it. It returns a *ValidationError listing every unknown column
+// and where clause applied to a column that is neither numeric nor a date.
+func (q *ExportQuery) Validate(ctx context.Context) error {
+	v, err := newValidator(ctx, (*query)(q))
+	if err != nil {
+		return err
+	}
+	v.checkSelect(q.params)
+	v.checkSort(q.params)
+	v.checkWhere(q.params)
+	v.checkSearch(q.params)
+	return v.err()
+}
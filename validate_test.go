@@ -0,0 +1,114 @@
+package enigma
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newMetaServer serves the metadata of a table made of testColumns and counts the requests received.
+func newMetaServer() (*httptest.Server, *int32) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		columns, _ := json.Marshal(testColumns)
+		fmt.Fprintf(w, `{"result": {"columns": %s}}`, columns)
+	}))
+	return server, &requests
+}
+
+func problems(t *testing.T, err error) []string {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatal("Expected a *ValidationError, got", err)
+	}
+	return validationErr.Problems
+}
+
+func TestDataQueryValidate(t *testing.T) {
+	server, requests := newMetaServer()
+	defer server.Close()
+	c := NewClient(key, WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	valid := c.Data(datapath).Select("namefull", "total_people").Sort("appt_made_date", Desc).Where("total_people>=5").Search("@namefull smith")
+	if err := valid.Validate(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	invalid := c.Data(datapath).Select("blablabla").Sort("nope", Asc).Where("namefull=smith").Where("ratio between 1 and 2").Where("??").Search("@who smith")
+	p := problems(t, invalid.Validate(ctx))
+	if len(p) != 5 {
+		t.Fatal(p)
+	}
+	for i, expected := range []string{`"blablabla" in select`, `"nope" in sort`, `"=" cannot be used on string column "namefull"`, `invalid where clause "??"`, `"who" in search`} {
+		if !strings.Contains(p[i], expected) {
+			t.Fatal(p[i], expected)
+		}
+	}
+
+	if *requests != 1 {
+		t.Fatal("Table metadata should be cached, fetched", *requests, "times")
+	}
+}
+
+func TestStatsQueryValidate(t *testing.T) {
+	server, _ := newMetaServer()
+	defer server.Close()
+	c := NewClient(key, WithBaseURL(server.URL))
+	ctx := context.Background()
+
+	for _, valid := range []*StatsQuery{
+		c.Stats(datapath, "total_people").Operation(StdDev),
+		c.Stats(datapath, "appt_made_date").Operation(Max),
+		c.Stats(datapath, "namefull").Operation(Frequency),
+		c.Stats(datapath, "namefull").By(Sum).Of("total_people"),
+	} {
+		if err := valid.Validate(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for expected, invalid := range map[string]*StatsQuery{
+		`operation "sum" cannot be run on date column`:   c.Stats(datapath, "appt_made_date").Operation(Sum),
+		`operation "avg" cannot be run on string column`: c.Stats(datapath, "namefull").Operation(Avg),
+		`unknown column "nope" in select`:                c.Stats(datapath, "nope"),
+		`requires Of()`:                                  c.Stats(datapath, "namefull").By(Avg),
+		`invalid compound operation "max"`:               c.Stats(datapath, "namefull").By(Max).Of("total_people"),
+		`compound operations require a numeric column`:   c.Stats(datapath, "namefull").By(Sum).Of("appt_made_date"),
+	} {
+		if p := problems(t, invalid.Validate(ctx)); len(p) != 1 || !strings.Contains(p[0], expected) {
+			t.Fatal(p, expected)
+		}
+	}
+}
+
+func TestExportQueryValidate(t *testing.T) {
+	server, _ := newMetaServer()
+	defer server.Close()
+	c := NewClient(key, WithBaseURL(server.URL))
+
+	if err := c.Export(datapath).Select("namefull").Where("appt_made_date>2013-01-01").Validate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if p := problems(t, c.Export(datapath).Select("namefull,nope").Validate(context.Background())); len(p) != 1 {
+		t.Fatal(p)
+	}
+}
+
+func TestValidateMetadataError(t *testing.T) {
+	server := newErrorServer(http.StatusNotFound, `{}`)
+	defer server.Close()
+
+	err := NewClient(key, WithBaseURL(server.URL)).Data(datapath).Validate(context.Background())
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatal("Expected an *APIError, got", err)
+	}
+}
@@ -0,0 +1,191 @@
+package enigma
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// walkConcurrency bounds the number of parent nodes fetched at once by Walk,
+// unless set with WithWalkConcurrency or capped by WithMaxInFlight.
+const walkConcurrency = 4
+
+// SkipNode can be returned by a WalkFunc to skip the children of a parent node.
+var SkipNode = errors.New("enigma: skip this node")
+
+// WalkNode is a node of the catalog visited by Walk.
+type WalkNode struct {
+	Datapath    string
+	Label       string
+	Description string
+	// Table is true for tables, and false for parent nodes.
+	Table bool
+	// Depth is the number of levels between the node and the root of the walk.
+	Depth int
+}
+
+// WalkFunc is called by Walk for every node visited.
+// Returning SkipNode for a parent node skips its children. Any other error stops the walk.
+type WalkFunc func(node WalkNode) error
+
+// WalkOption configures a call to Walk.
+type WalkOption func(*walker)
+
+// WithWalkConcurrency sets the maximum number of parent nodes fetched at the same time.
+func WithWalkConcurrency(n int) WalkOption {
+	return func(w *walker) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// WithWalkDepth stops the walk at depth levels below its root. By default, the walk has no limit.
+func WithWalkDepth(depth int) WalkOption {
+	return func(w *walker) {
+		w.maxDepth = depth
+	}
+}
+
+// Walk traverses the catalog from the parent node at root, calling fn for root and every
+// parent node and table below it, following every page of children tables.
+//
+// Parent nodes are fetched concurrently, but fn is never called concurrently.
+// Nodes are visited once, parents before their children.
+//    var tables []string
+//    err := client.Walk(ctx, "us.gov", func(node enigma.WalkNode) error {
+//        if node.Table {
+//            tables = append(tables, node.Datapath)
+//        }
+//        return nil
+//    }, enigma.WithWalkDepth(3))
+func (client *Client) Walk(ctx context.Context, root string, fn WalkFunc, options ...WalkOption) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	w := &walker{
+		client:   client,
+		fn:       fn,
+		ctx:      ctx,
+		cancel:   cancel,
+		maxDepth: -1,
+		visited:  make(map[string]bool),
+	}
+	w.concurrency = walkConcurrency
+	if client.limiter.slots != nil {
+		w.concurrency = cap(client.limiter.slots)
+	}
+	for _, option := range options {
+		option(w)
+	}
+	w.slots = make(chan struct{}, w.concurrency)
+
+	node, children, err := w.fetch(root, 0)
+	if err != nil {
+		return err
+	}
+	if w.visit(node) {
+		w.descend(children)
+	}
+	w.wg.Wait()
+
+	if w.err != nil {
+		return w.err
+	}
+	return ctx.Err()
+}
+
+// walker holds the state of a call to Walk.
+type walker struct {
+	client      *Client
+	fn          WalkFunc
+	ctx         context.Context
+	cancel      context.CancelFunc
+	concurrency int
+	maxDepth    int
+	slots       chan struct{}
+	wg          sync.WaitGroup
+
+	mu      sync.Mutex
+	visited map[string]bool
+	err     error
+}
+
+// visit calls fn for node, and reports whether its children should be visited.
+func (w *walker) visit(node WalkNode) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil || w.visited[node.Datapath] {
+		return false
+	}
+	w.visited[node.Datapath] = true
+
+	switch err := w.fn(node); err {
+	case nil:
+		return !node.Table && (w.maxDepth < 0 || node.Depth < w.maxDepth)
+	case SkipNode:
+	default:
+		w.fail(err)
+	}
+	return false
+}
+
+// descend visits children, and fetches the children of the parent nodes among them in the background.
+func (w *walker) descend(children []WalkNode) {
+	for _, child := range children {
+		if !w.visit(child) {
+			continue
+		}
+		w.wg.Add(1)
+		go func(child WalkNode) {
+			defer w.wg.Done()
+			select {
+			case w.slots <- struct{}{}:
+			case <-w.ctx.Done():
+				return
+			}
+			_, children, err := w.fetch(child.Datapath, child.Depth)
+			<-w.slots
+			if err != nil {
+				w.mu.Lock()
+				w.fail(err)
+				w.mu.Unlock()
+				return
+			}
+			w.descend(children)
+		}(child)
+	}
+}
+
+// fail records the first error of the walk and stops it. It must be called with w.mu held.
+func (w *walker) fail(err error) {
+	if w.err == nil {
+		w.err = err
+		w.cancel()
+	}
+}
+
+// fetch returns the parent node at datapath, and its children, reading every page of children tables.
+func (w *walker) fetch(datapath string, depth int) (WalkNode, []WalkNode, error) {
+	node := WalkNode{Datapath: datapath, Depth: depth}
+	var tables []WalkNode
+	it := w.client.Meta().ChildrenTables(w.ctx, datapath)
+	for it.Next() {
+		t := it.Table()
+		tables = append(tables, WalkNode{Datapath: t.Datapath, Label: t.Label, Description: t.Description, Table: true, Depth: depth + 1})
+	}
+	if err := it.Err(); err != nil {
+		return node, nil, err
+	}
+
+	var children []WalkNode
+	if parent := it.Parent(); parent != nil {
+		if path := parent.Result.Path; len(path) > 0 {
+			node.Label, node.Description = path[len(path)-1].Label, path[len(path)-1].Description
+		}
+		for _, n := range parent.Result.ImmediateNodes {
+			children = append(children, WalkNode{Datapath: n.Datapath, Label: n.Label, Description: n.Description, Depth: depth + 1})
+		}
+	}
+	return node, append(children, tables...), nil
+}
@@ -0,0 +1,125 @@
+package enigma
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// testCatalog maps parent datapaths to their immediate nodes and pages of children tables.
+var testCatalog = map[string]struct {
+	nodes  []string
+	tables [][]string
+}{
+	"us":            {nodes: []string{"us.gov", "us.state"}},
+	"us.gov":        {nodes: []string{"us.gov.agency"}, tables: [][]string{{"us.gov.a", "us.gov.b"}, {"us.gov.c"}}},
+	"us.state":      {tables: [][]string{{"us.state.x"}}},
+	"us.gov.agency": {tables: [][]string{{"us.gov.agency.t"}}},
+}
+
+func newCatalogServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		datapath := path.Base(r.URL.Path)
+		node, ok := testCatalog[datapath]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"info": {"message": "not found"}}`)
+			return
+		}
+		page := 1
+		fmt.Sscan(r.URL.Query().Get("page"), &page)
+
+		var nodes, tables []string
+		for _, n := range node.nodes {
+			nodes = append(nodes, fmt.Sprintf(`{"datapath": %q, "label": %q}`, n, strings.ToUpper(n)))
+		}
+		if page <= len(node.tables) {
+			for _, table := range node.tables[page-1] {
+				tables = append(tables, fmt.Sprintf(`{"datapath": %q}`, table))
+			}
+		}
+		fmt.Fprintf(w, `{"result": {"path": [{"label": %q}], "immediate_nodes": [%s], "children_tables": [%s]}, "info": {"current_page": %d, "total_pages": %d}}`,
+			strings.ToUpper(datapath), strings.Join(nodes, ","), strings.Join(tables, ","), page, len(node.tables))
+	}))
+}
+
+func TestWalk(t *testing.T) {
+	server := newCatalogServer()
+	defer server.Close()
+	c := NewClient(key, WithBaseURL(server.URL))
+
+	var visited []string
+	err := c.Walk(context.Background(), "us", func(node WalkNode) error {
+		kind := "node"
+		if node.Table {
+			kind = "table"
+		}
+		visited = append(visited, fmt.Sprintf("%s:%s:%d", kind, node.Datapath, node.Depth))
+		return nil
+	}, WithWalkConcurrency(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(visited)
+	expected := "node:us.gov.agency:2,node:us.gov:1,node:us.state:1,node:us:0," +
+		"table:us.gov.a:2,table:us.gov.agency.t:3,table:us.gov.b:2,table:us.gov.c:2,table:us.state.x:2"
+	if got := strings.Join(visited, ","); got != expected {
+		t.Fatal(got)
+	}
+}
+
+func TestWalkSkipAndDepth(t *testing.T) {
+	server := newCatalogServer()
+	defer server.Close()
+	c := NewClient(key, WithBaseURL(server.URL))
+
+	var visited []string
+	err := c.Walk(context.Background(), "us", func(node WalkNode) error {
+		visited = append(visited, node.Datapath)
+		if node.Datapath == "us.state" {
+			return SkipNode
+		}
+		if node.Datapath == "us" && node.Label != "US" {
+			t.Error("Unexpected label for the root:", node.Label)
+		}
+		return nil
+	}, WithWalkDepth(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sort.Strings(visited)
+	if got := strings.Join(visited, ","); got != "us,us.gov,us.state" {
+		t.Fatal(got)
+	}
+}
+
+func TestWalkErrors(t *testing.T) {
+	server := newCatalogServer()
+	defer server.Close()
+	c := NewClient(key, WithBaseURL(server.URL))
+
+	stop := errors.New("stop")
+	err := c.Walk(context.Background(), "us", func(node WalkNode) error {
+		if node.Datapath == "us.gov" {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatal("Expected the error returned by fn, got", err)
+	}
+
+	var apiErr *APIError
+	err = c.Walk(context.Background(), "nowhere", func(node WalkNode) error { return nil })
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Fatal("Expected an *APIError, got", err)
+	}
+}
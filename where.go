@@ -0,0 +1,203 @@
+package enigma
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// validColumn matches the IDs of columns.
+var validColumn = regexp.MustCompile(`^[A-Za-z0-9_]+$`)
+
+// plainNumber matches strings holding a decimal number, sent as is in where clauses.
+var plainNumber = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?$`)
+
+// negations maps each comparison operator to its opposite.
+var negations = map[string]string{
+	"=":  "!=",
+	"!=": "=",
+	">":  "<=",
+	"<=": ">",
+	">=": "<",
+	"<":  ">=",
+}
+
+// ColumnRef is a column on which a where clause can be built. See Col.
+type ColumnRef string
+
+// Col returns a reference to a column, used to build where clauses that can be passed
+// to the WhereClause method of DataQuery, StatsQuery and ExportQuery.
+//    enigma.Col("total_people").Gte(5)
+//    enigma.Col("total_people").In(1, 2, 3)
+//    enigma.Not(enigma.Col("appt_made_date").Between(start, end))
+//
+// Values can be integers, floats, time.Time, which are formatted using DateFormat
+// or DateTimeFormat, or strings, which are quoted unless they hold a decimal number or a date.
+func Col(column string) ColumnRef {
+	return ColumnRef(column)
+}
+
+// Eq matches rows where the column is equal to value.
+func (c ColumnRef) Eq(value interface{}) Clause {
+	return c.compare("=", value)
+}
+
+// Ne matches rows where the column is not equal to value.
+func (c ColumnRef) Ne(value interface{}) Clause {
+	return c.compare("!=", value)
+}
+
+// Gt matches rows where the column is greater than value.
+func (c ColumnRef) Gt(value interface{}) Clause {
+	return c.compare(">", value)
+}
+
+// Gte matches rows where the column is greater than or equal to value.
+func (c ColumnRef) Gte(value interface{}) Clause {
+	return c.compare(">=", value)
+}
+
+// Lt matches rows where the column is lower than value.
+func (c ColumnRef) Lt(value interface{}) Clause {
+	return c.compare("<", value)
+}
+
+// Lte matches rows where the column is lower than or equal to value.
+func (c ColumnRef) Lte(value interface{}) Clause {
+	return c.compare("<=", value)
+}
+
+// In matches rows where the column is equal to one of the given values.
+func (c ColumnRef) In(values ...interface{}) Clause {
+	if len(values) == 0 {
+		return c.clause("in", nil, fmt.Errorf("enigma: no value given to in clause on %q", string(c)))
+	}
+	return c.clause("in", values, nil)
+}
+
+// Between matches rows where the column lies between min and max, inclusive.
+func (c ColumnRef) Between(min, max interface{}) Clause {
+	return c.clause("between", []interface{}{min, max}, nil)
+}
+
+func (c ColumnRef) compare(operator string, value interface{}) Clause {
+	return c.clause(operator, []interface{}{value}, nil)
+}
+
+// clause formats values and validates the column name to build a Clause.
+func (c ColumnRef) clause(operator string, values []interface{}, err error) Clause {
+	clause := Clause{column: string(c), operator: operator, err: err}
+	if clause.err == nil && !validColumn.MatchString(clause.column) {
+		clause.err = fmt.Errorf("enigma: invalid column %q in where clause", clause.column)
+	}
+	for _, v := range values {
+		if clause.err != nil {
+			break
+		}
+		var s string
+		s, clause.err = formatValue(v)
+		clause.values = append(clause.values, s)
+	}
+	return clause
+}
+
+// Clause is a where clause built with Col.
+type Clause struct {
+	column   string
+	operator string // Comparison operator, "in" or "between".
+	values   []string
+	not      bool
+	err      error
+}
+
+// Not negates a where clause.
+func Not(clause Clause) Clause {
+	if negation, ok := negations[clause.operator]; ok {
+		clause.operator = negation
+	} else {
+		clause.not = !clause.not
+	}
+	return clause
+}
+
+// Err returns the error, if any, that made the clause invalid.
+// Invalid clauses make queries fail with that error.
+func (c Clause) Err() error {
+	return c.err
+}
+
+// String returns the clause in the format expected by the API.
+func (c Clause) String() string {
+	not := ""
+	if c.not {
+		not = "not "
+	}
+	switch c.operator {
+	case "in":
+		return c.column + " " + not + "in (" + strings.Join(c.values, ",") + ")"
+	case "between":
+		if len(c.values) != 2 {
+			return ""
+		}
+		return c.column + " " + not + "between " + c.values[0] + " and " + c.values[1]
+	}
+	if len(c.values) != 1 {
+		return ""
+	}
+	return c.column + c.operator + c.values[0]
+}
+
+// formatValue formats a value of a where clause.
+func formatValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return formatFloat(float64(v), 32)
+	case float64:
+		return formatFloat(v, 64)
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format(DateFormat), nil
+		}
+		return v.Format(DateTimeFormat), nil
+	case string:
+		if plainNumber.MatchString(v) {
+			return v, nil
+		}
+		if _, err := parseDate(v); err == nil {
+			return v, nil
+		}
+		return "'" + strings.Replace(v, "'", "''", -1) + "'", nil
+	}
+	return "", fmt.Errorf("enigma: unsupported value %v of type %T in where clause", v, v)
+}
+
+// formatFloat formats v in decimal notation. NaN and infinities have no representation in where clauses.
+func formatFloat(v float64, bitSize int) (string, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return "", fmt.Errorf("enigma: unsupported value %v in where clause", v)
+	}
+	return strconv.FormatFloat(v, 'f', -1, bitSize), nil
+}
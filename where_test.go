@@ -0,0 +1,83 @@
+package enigma
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestClause(t *testing.T) {
+	date := time.Date(2013, 5, 14, 0, 0, 0, 0, time.UTC)
+	clauses := map[string]Clause{
+		"total_people>=5":                         Col("total_people").Gte(5),
+		"total_people>2.5":                        Col("total_people").Gt(2.5),
+		"total_people<10":                         Col("total_people").Lt(int64(10)),
+		"total_people<=10":                        Col("total_people").Lte(uint8(10)),
+		"total_people=3":                          Col("total_people").Eq("3"),
+		"total_people!=3":                         Col("total_people").Ne(3),
+		"appt_made_date>2013-05-14":               Col("appt_made_date").Gt(date),
+		"appt_made_date>2013-05-14T10:30:00":      Col("appt_made_date").Gt(date.Add(630 * time.Minute)),
+		"x in (1,2,3)":                            Col("x").In(1, 2, 3),
+		"x not in (1,2,3)":                        Not(Col("x").In(1, 2, 3)),
+		"d between 2013-05-14 and 2013-05-15":     Col("d").Between(date, date.AddDate(0, 0, 1)),
+		"d not between 2013-05-14 and 2013-05-15": Not(Col("d").Between(date, "2013-05-15")),
+		"total_people<5":                          Not(Col("total_people").Gte(5)),
+		"total_people=5":                          Not(Not(Col("total_people").Eq(5))),
+		"name='O''Brien, x) or (1=1'":             Col("name").Eq("O'Brien, x) or (1=1"),
+		"total_people>-2.5":                       Col("total_people").Gt("-2.5"),
+		"name='NaN'":                              Col("name").Eq("NaN"),
+		"name='Inf'":                              Col("name").Eq("Inf"),
+		"name='infinity'":                         Col("name").Eq("infinity"),
+		"name='1e5'":                              Col("name").Eq("1e5"),
+		"name='0x1F'":                             Col("name").Eq("0x1F"),
+	}
+	for expected, clause := range clauses {
+		if err := clause.Err(); err != nil {
+			t.Fatal(expected, err)
+		}
+		if clause.String() != expected {
+			t.Fatal(expected, clause.String())
+		}
+	}
+}
+
+func TestClauseErrors(t *testing.T) {
+	for _, clause := range []Clause{
+		Col("total people").Gte(5),
+		Col("").Eq(1),
+		Col("x").In(),
+		Col("x").Eq(struct{}{}),
+		Col("x").Eq(math.NaN()),
+		Col("x").Gt(math.Inf(1)),
+		Col("x").Lt(float32(math.Inf(-1))),
+	} {
+		if clause.Err() == nil {
+			t.Fatal("Expected error was not returned for", clause)
+		}
+	}
+}
+
+func TestWhereClause(t *testing.T) {
+	if query := client.Data(datapath).WhereClause(Col("total_people").Gte(5)); query.params.Get("where") != "total_people>=5" {
+		t.Fatal("Parameter was not properly added to the query")
+	}
+	if query := client.Stats(datapath, "column").WhereClause(Col("x").In(1, 2)); query.params.Get("where") != "x in (1,2)" {
+		t.Fatal("Parameter was not properly added to the query")
+	}
+	if query := client.Export(datapath).WhereClause(Col("x").Between(1, 2)); query.params.Get("where") != "x between 1 and 2" {
+		t.Fatal("Parameter was not properly added to the query")
+	}
+}
+
+func TestWhereClauseError(t *testing.T) {
+	query := client.Data(datapath).WhereClause(Col("total people").Gte(5))
+	if query.params.Get("where") != "" {
+		t.Fatal("Invalid clauses should not be added to the query")
+	}
+	if _, err := query.Results(); err != query.err || err == nil {
+		t.Fatal("Expected the clause error to be returned, got", err)
+	}
+	if _, err := client.Export(datapath).WhereClause(Col("").Eq(1)).FileURL(nil); err == nil {
+		t.Fatal("Expected error was not returned")
+	}
+}